@@ -1,89 +1,244 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"log/slog"
+	"net"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	_ "github.com/marcboeker/go-duckdb"
+	"google.golang.org/grpc"
 
 	"github.com/ilmimris/wilayah-indonesia/internal/api"
+	"github.com/ilmimris/wilayah-indonesia/internal/auth"
+	"github.com/ilmimris/wilayah-indonesia/internal/buildinfo"
+	"github.com/ilmimris/wilayah-indonesia/internal/config"
+	"github.com/ilmimris/wilayah-indonesia/internal/grpcserver"
+	"github.com/ilmimris/wilayah-indonesia/internal/logging"
+	"github.com/ilmimris/wilayah-indonesia/internal/metrics"
+	"github.com/ilmimris/wilayah-indonesia/internal/ratelimit"
+	"github.com/ilmimris/wilayah-indonesia/internal/tracing"
 	"github.com/ilmimris/wilayah-indonesia/pkg/service"
 )
 
 func main() {
-	// Set up structured logging
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	// Load the configuration from defaults, the -config file, the environment and flags
+	cfg, err := config.Load(os.Args[1:], os.Getenv, os.Stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(2)
+	}
+
+	// Set up structured logging, as text or JSON at the configured level
+	logger, err := logging.New(os.Stdout, logging.Config{
+		Format:        cfg.Logging.Format,
+		Level:         cfg.Logging.Level,
+		RedactQueries: cfg.Logging.RedactQueries,
+	})
+	if err != nil {
+		slog.Error("Failed to set up logging", "error", err)
+		os.Exit(1)
+	}
 	slog.SetDefault(logger)
+	build := buildinfo.Get()
+	slog.Info("Starting wilayah-indonesia", "version", build.Version, "commit", build.Commit, "go_version", build.GoVersion)
+	slog.Info("Effective configuration", "config", cfg)
 
-	// Get database path from environment variable or default to data/regions.duckdb
-	dbPath := os.Getenv("DB_PATH")
-	if dbPath == "" {
-		dbPath = "data/regions.duckdb"
+	// Install the tracer provider of the configured exporter (otlp, stdout or none)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter)
+	if err != nil {
+		slog.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
 	}
 
 	// Open a read-only connection to the database file
-	db, err := sql.Open("duckdb", dbPath+"?access_mode=read_only")
+	db, err := sql.Open("duckdb", cfg.Database.Path+"?access_mode=read_only")
 	if err != nil {
 		slog.Error("Failed to open database connection", "error", err)
 		os.Exit(1)
 	}
-	defer db.Close()
+
+	// Export connection pool statistics with the other metrics
+	if err := metrics.RegisterDB(db, "duckdb"); err != nil {
+		slog.Error("Failed to register database metrics", "error", err)
+		os.Exit(1)
+	}
 
 	// Create service and handler instances
-	svc := service.New(db)
-	handler := api.New(svc)
+	similarity := cfg.Search.Similarity
+	svc := service.New(db,
+		service.WithObserver(metrics.ServiceObserver{}),
+		service.WithLogger(logger),
+		service.WithSearchLimits(cfg.Search.DefaultLimit, cfg.Search.MaxLimit),
+		service.WithSearchCache(cfg.Search.CacheSize),
+		service.WithReadinessCache(cfg.Server.ReadinessCacheTTL),
+		service.WithReadinessTimeout(cfg.Server.ReadinessTimeout),
+		service.WithSimilarityThreshold(service.SearchDistrict, similarity.District),
+		service.WithSimilarityThreshold(service.SearchSubdistrict, similarity.Subdistrict),
+		service.WithSimilarityThreshold(service.SearchCity, similarity.City),
+		service.WithSimilarityThreshold(service.SearchProvince, similarity.Province),
+		service.WithSimilarityThreshold(service.SimilarityIslands, similarity.Islands),
+	)
+
+	// Limit each client per route group; the limits were validated with the configuration
+	limits := make(map[string]ratelimit.Limit)
+	for group, value := range map[string]string{
+		ratelimit.GroupSearch: cfg.RateLimit.Search,
+		ratelimit.GroupExport: cfg.RateLimit.Export,
+		ratelimit.GroupBatch:  cfg.RateLimit.Batch,
+	} {
+		limits[group], _ = ratelimit.ParseLimit(value)
+	}
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), limits)
+	handlerOpts := []api.Option{
+		api.WithRateLimiter(limiter),
+		api.WithCacheMaxAge(api.CacheSearch, cfg.HTTPCache.SearchMaxAge),
+		api.WithCacheMaxAge(api.CacheLookup, cfg.HTTPCache.LookupMaxAge),
+		api.WithCacheMaxAge(api.CacheExport, cfg.HTTPCache.ExportMaxAge),
+		api.WithExportWriteTimeout(cfg.Server.ExportWriteTimeout),
+	}
 
-	// Set up a new Fiber application
-	app := fiber.New()
+	// Require API keys when any are defined in the keys file or the keys setting
+	keyring, err := auth.Load(cfg.Auth.KeysFile, cfg.Auth.Keys, auth.WithObserver(metrics.AuthObserver{}))
+	if err != nil {
+		slog.Error("Failed to load API keys", "error", err)
+		os.Exit(1)
+	}
+	if keyring.Len() > 0 {
+		slog.Info("API key authentication enabled", "keys", keyring.Len())
+		handlerOpts = append(handlerOpts, api.WithKeyring(keyring))
+		if cfg.Auth.ProtectMetrics {
+			handlerOpts = append(handlerOpts, api.WithProtectedMetrics())
+		}
+	}
+	handler := api.New(svc, handlerOpts...)
+
+	// Set up a new Fiber application. Behind a proxy, the proxy header names
+	// the header carrying the client IP, such as X-Forwarded-For. The
+	// timeouts and body limit bound slow or oversized requests.
+	app := fiber.New(fiber.Config{
+		ErrorHandler:       api.ErrorHandler,
+		ProxyHeader:        cfg.Server.ProxyHeader,
+		EnableIPValidation: true,
+		ReadTimeout:        cfg.Server.ReadTimeout,
+		WriteTimeout:       cfg.Server.WriteTimeout,
+		IdleTimeout:        cfg.Server.IdleTimeout,
+		BodyLimit:          cfg.Server.BodyLimit,
+	})
 
-	// Define the search endpoint
-	app.Get("/v1/search", handler.SearchHandler())
+	// Answer preflight requests and allow the configured origins to read responses
+	if len(cfg.CORS.AllowOrigins) > 0 {
+		app.Use(cors.New(cors.Config{
+			AllowOrigins:  strings.Join(cfg.CORS.AllowOrigins, ","),
+			AllowMethods:  strings.Join([]string{fiber.MethodGet, fiber.MethodHead, fiber.MethodPost}, ","),
+			AllowHeaders:  strings.Join([]string{fiber.HeaderContentType, fiber.HeaderAuthorization, auth.HeaderAPIKey, fiber.HeaderXRequestID, fiber.HeaderIfNoneMatch}, ","),
+			ExposeHeaders: strings.Join([]string{fiber.HeaderXRequestID, fiber.HeaderETag, ratelimit.HeaderLimit, ratelimit.HeaderRemaining, ratelimit.HeaderReset, fiber.HeaderRetryAfter}, ","),
+			MaxAge:        int(cfg.CORS.MaxAge.Seconds()),
+		}))
+	}
 
-	// Define the district search endpoint
-	app.Get("/v1/search/district", handler.DistrictSearchHandler())
+	// Assign every request an ID, reusing the caller's X-Request-ID when present
+	app.Use(requestid.New())
 
-	// Define the subdistrict search endpoint
-	app.Get("/v1/search/subdistrict", handler.SubdistrictSearchHandler())
+	// Trace every request, continuing the caller's W3C trace context
+	app.Use(tracing.Middleware())
 
-	// Define the city search endpoint
-	app.Get("/v1/search/city", handler.CitySearchHandler())
+	// Log every request with its ID, status and latency
+	app.Use(logging.Middleware(logger))
 
-	// Define the province search endpoint
-	app.Get("/v1/search/province", handler.ProvinceSearchHandler())
+	// Record request counts and latency per route
+	app.Use(metrics.Middleware())
 
-	// Define the postal code search endpoint
-	app.Get("/v1/search/postal/:postalCode", handler.PostalCodeSearchHandler())
+	// Register the API routes
+	api.RegisterRoutes(app, handler)
 
-	// Add health check endpoint
-	app.Get("/healthz", func(c *fiber.Ctx) error {
-		// Check database connection
-		err := db.Ping()
-		if err != nil {
-			slog.Error("Database connection failed in health check", "error", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Database connection failed",
-			})
+	// Start the gRPC server alongside Fiber, sharing the same service
+	lis, err := net.Listen("tcp", cfg.Server.GRPCAddr)
+	if err != nil {
+		slog.Error("Failed to listen for gRPC", "error", err, "addr", cfg.Server.GRPCAddr)
+		os.Exit(1)
+	}
+	grpcOpts := []grpc.ServerOption{tracing.GRPCServerOption()}
+	if keyring.Len() > 0 {
+		grpcOpts = append(grpcOpts, grpcserver.AuthOptions(keyring)...)
+	}
+	grpcOpts = append(grpcOpts, grpcserver.RateLimitOptions(limiter)...)
+	grpcServer, healthServer := grpcserver.NewGRPCServer(svc, grpcOpts...)
+	go func() {
+		slog.Info("gRPC server starting", "addr", cfg.Server.GRPCAddr)
+		if err := grpcServer.Serve(lis); err != nil {
+			slog.Error("gRPC server failed", "error", err)
+			os.Exit(1)
 		}
-		return c.JSON(fiber.Map{
-			"status":  "ok",
-			"message": "Service is healthy",
-		})
-	})
+	}()
+
+	// Start the Fiber server on the configured address
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server starting", "addr", cfg.Server.Addr)
+		serverErr <- app.Listen(cfg.Server.Addr)
+	}()
+
+	// Run until SIGINT or SIGTERM, which Kubernetes sends before stopping the pod
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-serverErr:
+		slog.Error("Failed to start server", "error", err)
+		os.Exit(1)
+	case <-ctx.Done():
+		stop()
+	}
 
-	// Get port from environment variable or default to 8080
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Report the service as unavailable first and give load balancers the
+	// shutdown delay to stop routing to it, then let in-flight requests finish
+	// within the shutdown timeout
+	drainDelay := cfg.Server.ShutdownDelay
+	shutdownTimeout := cfg.Server.ShutdownTimeout
+	slog.Info("Shutting down", "delay", drainDelay, "timeout", shutdownTimeout)
+	handler.SetReady(false)
+	healthServer.Shutdown()
+	time.Sleep(drainDelay)
+
+	if err := app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+		slog.Error("Failed to drain HTTP requests", "error", err)
 	}
+	stopGRPC(grpcServer, shutdownTimeout)
 
-	// Start the Fiber server on a configurable port
-	slog.Info("Server starting", "port", port)
-	err = app.Listen(":" + port)
-	if err != nil {
-		slog.Error("Failed to start server", "error", err)
-		os.Exit(1)
+	if err := shutdownTracing(context.Background()); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	if err := db.Close(); err != nil {
+		slog.Error("Failed to close database", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// stopGRPC waits up to timeout for in-flight RPCs and streams to finish, then
+// closes the remaining connections.
+func stopGRPC(srv *grpc.Server, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		slog.Error("Failed to drain gRPC calls, closing them", "timeout", timeout)
+		srv.Stop()
 	}
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/ingest"
+)
+
+// runDiff compares two dataset builds and writes a changelog.
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	oldPath := fs.String("old", "", "previous build: a regions.duckdb file or a wilayah SQL dump")
+	newPath := fs.String("new", defaultDBPath, "new build: a regions.duckdb file or a wilayah SQL dump")
+	oldPostal := fs.String("old-postal", "", "wilayah_kodepos SQL dump for an SQL -old build")
+	newPostal := fs.String("new-postal", "", "wilayah_kodepos SQL dump for an SQL -new build")
+	format := fs.String("format", "markdown", "output format: json, csv or markdown")
+	output := fs.String("o", "", "write the changelog to this file instead of stdout")
+	fs.Parse(args)
+
+	if *oldPath == "" {
+		return errors.New("diff: -old is required")
+	}
+
+	var write func(*ingest.Changelog, io.Writer) error
+	switch *format {
+	case "json":
+		write = (*ingest.Changelog).WriteJSON
+	case "csv":
+		write = (*ingest.Changelog).WriteCSV
+	case "markdown", "md":
+		write = (*ingest.Changelog).WriteMarkdown
+	default:
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+
+	changelog, err := ingest.Diff(
+		ingest.Snapshot{Path: *oldPath, PostalPath: *oldPostal},
+		ingest.Snapshot{Path: *newPath, PostalPath: *newPostal},
+	)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return write(changelog, os.Stdout)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := write(changelog, f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/ingest"
+)
+
+// runExport writes a table of the regions database to a file.
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "path of the DuckDB database to read")
+	table := fs.String("table", "regions", "table to export")
+	columns := fs.String("columns", "", "comma-separated columns to export (default all)")
+	province := fs.String("province", "", "only export rows under this province code, e.g. 32")
+	city := fs.String("city", "", "only export rows under this city code, e.g. 32.73")
+	format := fs.String("format", "", "output format: "+strings.Join(ingest.ExportFormats, ", ")+" (default from -o extension)")
+	output := fs.String("o", "", "output file")
+	fs.Parse(args)
+
+	if *output == "" {
+		return errors.New("export: -o is required")
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*output)), ".")
+		if *format == "jsonl" {
+			*format = ingest.FormatNDJSON
+		}
+	}
+
+	var cols []string
+	for _, c := range strings.Split(*columns, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			cols = append(cols, c)
+		}
+	}
+
+	db, err := sql.Open("duckdb", *dbPath+"?access_mode=read_only")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	n, err := ingest.Export(db, *output, ingest.ExportOptions{
+		Table:    *table,
+		Columns:  cols,
+		Province: *province,
+		City:     *city,
+		Format:   *format,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d rows from %s to %s\n", n, *table, *output)
+	return nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/ingest"
+)
+
+// runIngest builds the regions database from the upstream SQL dumps.
+func runIngest(args []string) error {
+	fs := flag.NewFlagSet("ingest", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "path of the DuckDB database to create")
+	sources := addSourceFlags(fs)
+	statsPath := fs.String("stats", "", "optional wilayah_level_1_2 SQL dump with coordinates, area and population")
+	boundariesPath := fs.String("boundaries", "", "optional wilayah_boundaries SQL dump, or a directory of dumps")
+	islandsPath := fs.String("islands", "", "optional pulau SQL dump, or a directory of dumps, with the islands of each city")
+	attributesDir := fs.String("attributes", "", "directory of attribute CSV files keyed by region code to load")
+	inheritAttributes := fs.String("inherit-attributes", "", "comma-separated attribute sets whose values apply to the regions below their code")
+	reportPath := fs.String("report", "", "write the validation report as JSON to this file")
+	skipValidation := fs.Bool("skip-validation", false, "do not fail when validation thresholds are exceeded")
+	thresholds := newThresholdFlag()
+	fs.Var(thresholds, "threshold", "maximum issues allowed for a check, as check=N (repeatable, -1 disables)")
+	fs.Parse(args)
+
+	// Connect to a new or existing DuckDB file
+	db, err := sql.Open("duckdb", *dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	// Create and populate the raw wilayah and wilayah_kodepos tables
+	if err := sources.load(db); err != nil {
+		return err
+	}
+
+	// Load the optional province and city statistics and islands
+	for _, path := range []string{*statsPath, *boundariesPath, *islandsPath} {
+		if path != "" {
+			if err := ingest.LoadSQLFiles(db, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Validate the raw tables before they are denormalized and dropped
+	report, err := ingest.Validate(db, ingest.ValidateOptions{Thresholds: thresholds.values})
+	if err != nil {
+		return err
+	}
+	if err := report.WriteText(os.Stdout); err != nil {
+		return err
+	}
+	if *reportPath != "" {
+		if err := writeReportFile(report, *reportPath); err != nil {
+			return err
+		}
+	}
+	if !report.Passed && !*skipValidation {
+		return fmt.Errorf("validation failed: %s", strings.Join(report.Failures(), ", "))
+	}
+
+	// Denormalize the data, clean up the raw tables and index the result
+	if err := ingest.BuildRegions(db); err != nil {
+		return err
+	}
+	if err := ingest.BuildRegionStats(db); err != nil {
+		return err
+	}
+	if err := ingest.BuildIslands(db); err != nil {
+		return err
+	}
+	if *attributesDir != "" {
+		var inherit []string
+		for _, name := range strings.Split(*inheritAttributes, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				inherit = append(inherit, name)
+			}
+		}
+		names, err := ingest.LoadAttributeFiles(db, *attributesDir, inherit)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Loaded attribute sets: %s\n", strings.Join(names, ", "))
+	}
+	version, err := ingest.WriteMetadata(db, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := ingest.DropRawTables(db); err != nil {
+		return err
+	}
+	if err := ingest.CreateSearchIndex(db); err != nil {
+		return err
+	}
+
+	fmt.Printf("Data ingestion and preparation completed successfully with postal codes! (dataset version %s)\n", version)
+	return nil
+}
+
+// writeReportFile writes report as JSON to path.
+func writeReportFile(report *ingest.Report, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	if err := report.WriteJSON(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return f.Close()
+}
+
+// thresholdFlag collects repeated check=N flags on top of the default thresholds.
+type thresholdFlag struct {
+	values map[string]int
+}
+
+func newThresholdFlag() *thresholdFlag {
+	return &thresholdFlag{values: ingest.DefaultThresholds()}
+}
+
+func (f *thresholdFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	var parts []string
+	for _, name := range ingest.CheckNames() {
+		if v, ok := f.values[name]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%d", name, v))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *thresholdFlag) Set(value string) error {
+	name, limit, ok := strings.Cut(value, "=")
+	if !ok {
+		return errors.New("expected check=N")
+	}
+	known := false
+	for _, n := range ingest.CheckNames() {
+		known = known || n == name
+	}
+	if !known {
+		return fmt.Errorf("unknown check %q (known: %s)", name, strings.Join(ingest.CheckNames(), ", "))
+	}
+	var n int
+	if _, err := fmt.Sscanf(limit, "%d", &n); err != nil || n < ingest.NoThreshold {
+		return fmt.Errorf("invalid threshold %q", limit)
+	}
+	f.values[name] = n
+	return nil
+}
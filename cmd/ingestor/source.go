@@ -0,0 +1,71 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"strings"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/ingest"
+)
+
+// sourceFlags selects the input files loaded into the raw wilayah and wilayah_kodepos tables.
+type sourceFlags struct {
+	sqlPath      string
+	postalPath   string
+	source       string
+	postalSource string
+	mappingPath  string
+}
+
+// addSourceFlags registers the input flags shared by the commands that read the raw data.
+func addSourceFlags(fs *flag.FlagSet) *sourceFlags {
+	s := &sourceFlags{}
+	fs.StringVar(&s.sqlPath, "sql", defaultSQLPath, "path of the wilayah SQL dump")
+	fs.StringVar(&s.postalPath, "postal", defaultPostalPath, "path of the wilayah_kodepos SQL dump")
+	fs.StringVar(&s.source, "source", "", "CSV, JSON, NDJSON or XLSX file to load instead of the SQL dumps")
+	fs.StringVar(&s.postalSource, "postal-source", "", "CSV, JSON, NDJSON or XLSX file with postal codes for -source")
+	fs.StringVar(&s.mappingPath, "mapping", "", "JSON file mapping the code, name and postal_code columns of -source")
+	return s
+}
+
+// load creates the raw tables from either the SQL dumps or the tabular source files.
+func (s *sourceFlags) load(db *sql.DB) error {
+	// Start from empty raw tables so a previous failed run cannot leak into this one
+	if err := ingest.DropRawTables(db); err != nil {
+		return err
+	}
+
+	if s.source == "" {
+		if err := ingest.LoadSQLFile(db, s.sqlPath); err != nil {
+			return err
+		}
+		return ingest.LoadSQLFile(db, s.postalPath)
+	}
+
+	mapping := ingest.DefaultMapping()
+	if s.mappingPath != "" {
+		var err error
+		if mapping, err = ingest.LoadMapping(s.mappingPath); err != nil {
+			return err
+		}
+	}
+
+	if err := s.loadFile(db, s.source, mapping); err != nil {
+		return err
+	}
+	if s.postalSource != "" {
+		// The postal file only contributes postal codes
+		postalMapping := mapping
+		postalMapping.Name = ""
+		return s.loadFile(db, s.postalSource, postalMapping)
+	}
+	return nil
+}
+
+// loadFile loads one source file, accepting SQL dumps as well as tabular files.
+func (s *sourceFlags) loadFile(db *sql.DB, path string, mapping ingest.Mapping) error {
+	if strings.HasSuffix(strings.ToLower(path), ".sql") {
+		return ingest.LoadSQLFile(db, path)
+	}
+	return ingest.LoadTabularFile(db, path, mapping)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/ingest"
+)
+
+// runValidate loads the source data into an in-memory database and reports data-quality issues.
+// It exits with an error when any check exceeds its threshold.
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	sources := addSourceFlags(fs)
+	format := fs.String("format", "text", "report format: text or json")
+	samples := fs.Int("samples", ingest.DefaultSampleLimit, "number of issues listed per check")
+	thresholds := newThresholdFlag()
+	fs.Var(thresholds, "threshold", "maximum issues allowed for a check, as check=N (repeatable, -1 disables)")
+	fs.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		return fmt.Errorf("failed to open in-memory database: %w", err)
+	}
+	defer db.Close()
+
+	if err := sources.load(db); err != nil {
+		return err
+	}
+
+	report, err := ingest.Validate(db, ingest.ValidateOptions{
+		Thresholds:  thresholds.values,
+		SampleLimit: *samples,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !report.Passed {
+		return fmt.Errorf("validation failed: %s", strings.Join(report.Failures(), ", "))
+	}
+	return nil
+}
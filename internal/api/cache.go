@@ -0,0 +1,95 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Route groups with their own Cache-Control max-age.
+const (
+	CacheSearch = "search"
+	CacheLookup = "lookup"
+	CacheExport = "export"
+)
+
+// WithCacheMaxAge lets clients and shared caches reuse the responses of a
+// route group for maxAge before revalidating them. Without it, responses
+// must be revalidated on every use, which their ETag keeps cheap.
+func WithCacheMaxAge(group string, maxAge time.Duration) Option {
+	return func(h *Handler) {
+		h.cacheMaxAge[group] = maxAge
+	}
+}
+
+// httpCache returns the middleware that makes the successful responses of a
+// route group cacheable. Responses only change with the dataset, so their
+// ETag combines the dataset version with the path and query of the request,
+// and a request whose If-None-Match header matches it gets 304 Not Modified
+// without running the handler.
+func (h *Handler) httpCache(group string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		version, err := h.svc.DatasetVersion(c.UserContext())
+		if err != nil {
+			// Leave the failure to the handler, which reports it
+			return c.Next()
+		}
+		etag := requestETag(c, version)
+		cacheControl := h.cacheControl(group)
+
+		if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+			if group != CacheExport {
+				c.Vary(fiber.HeaderAccept)
+			}
+			c.Set(fiber.HeaderETag, etag)
+			c.Set(fiber.HeaderCacheControl, cacheControl)
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() == fiber.StatusOK {
+			c.Set(fiber.HeaderETag, etag)
+			c.Set(fiber.HeaderCacheControl, cacheControl)
+		}
+		return nil
+	}
+}
+
+// cacheControl returns the Cache-Control header of a route group. With API
+// keys, shared caches must not store responses, or they would serve them to
+// clients without a key.
+func (h *Handler) cacheControl(group string) string {
+	visibility := "public"
+	if h.keyring != nil {
+		visibility = "private"
+	}
+	maxAge := h.cacheMaxAge[group]
+	if maxAge <= 0 {
+		return visibility + ", no-cache"
+	}
+	return visibility + ", max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+}
+
+// requestETag returns the weak ETag of the response to c for the dataset
+// version. The query parameters are sorted so that their order does not
+// matter, and the negotiated format is included so that every format has
+// its own ETag. The ETag is weak because enveloped responses report their
+// own timing.
+func requestETag(c *fiber.Ctx, version string) string {
+	var params []string
+	c.Request().URI().QueryArgs().VisitAll(func(key, value []byte) {
+		params = append(params, string(key)+"="+string(value))
+	})
+	slices.Sort(params)
+	format, _ := responseFormat(c)
+
+	sum := sha256.Sum256([]byte(c.Path() + "?" + strings.Join(params, "&") + "#" + format))
+	return fmt.Sprintf(`W/"%s-%s"`, version, hex.EncodeToString(sum[:8]))
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// openAPISpec is the OpenAPI 3 document describing every route in RegisterRoutes.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// docsPage loads the embedded Swagger UI assets and points them at /openapi.json,
+// so the documentation works without access to external resources.
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>Indonesian Regions API Documentation</title>
+  <link rel="stylesheet" type="text/css" href="/docs/swagger-ui.css">
+  <link rel="icon" type="image/png" href="/docs/favicon-32x32.png" sizes="32x32">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="/docs/swagger-ui-bundle.js" charset="UTF-8"></script>
+  <script>
+    window.onload = function() {
+      window.ui = SwaggerUIBundle({
+        url: "/openapi.json",
+        dom_id: "#swagger-ui",
+        deepLinking: true
+      });
+    };
+  </script>
+</body>
+</html>
+`
+
+// OpenAPISpec returns the embedded OpenAPI 3 document.
+func OpenAPISpec() []byte {
+	return openAPISpec
+}
+
+// OpenAPIHandler serves the OpenAPI specification.
+func OpenAPIHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Send(openAPISpec)
+	}
+}
+
+// DocsHandler serves the documentation UI page.
+func DocsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.SendString(docsPage)
+	}
+}
+
+// DocsAssetsHandler serves the embedded Swagger UI assets below /docs.
+func DocsAssetsHandler() fiber.Handler {
+	return filesystem.New(filesystem.Config{
+		Root: http.FS(swaggerFiles.FS),
+	})
+}
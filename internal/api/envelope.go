@@ -0,0 +1,101 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+// envelopeKey marks requests whose results are wrapped in an Envelope.
+const envelopeKey = "envelope"
+
+// Envelope is the response shape of the /v2 search endpoints, also returned
+// by /v1 with ?envelope=true.
+type Envelope struct {
+	Data interface{} `json:"data"`
+	Meta Meta        `json:"meta"`
+}
+
+// Meta describes the query behind an enveloped response. NormalizedQuery is
+// the query as searched, and Expansions the queries it was expanded to, such
+// as "Kota bandung" and "Kabupaten bandung" for a city search.
+type Meta struct {
+	Query           string   `json:"query"`
+	NormalizedQuery string   `json:"normalized_query"`
+	Expansions      []string `json:"expansions,omitempty" xml:"expansion"`
+	Total           int      `json:"total"`
+	Limit           int      `json:"limit"`
+	Offset          int      `json:"offset"`
+	TookMs          float64  `json:"took_ms"`
+	DatasetVersion  string   `json:"dataset_version"`
+}
+
+// UseEnvelope is a middleware that wraps the results of every search
+// endpoint below it in an Envelope.
+func UseEnvelope() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(envelopeKey, true)
+		return c.Next()
+	}
+}
+
+// wantsEnvelope reports whether the results of the request are wrapped in an Envelope.
+func wantsEnvelope(c *fiber.Ctx) bool {
+	if enveloped, _ := c.Locals(envelopeKey).(bool); enveloped {
+		return true
+	}
+	return c.QueryBool("envelope")
+}
+
+// searchOptions parses the limit and offset query parameters.
+func searchOptions(c *fiber.Ctx) (service.SearchOptions, error) {
+	var opts service.SearchOptions
+	for _, p := range []struct {
+		name  string
+		value *int
+	}{{"limit", &opts.Limit}, {"offset", &opts.Offset}} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return opts, service.NewFieldError(p.name, "Query parameter '"+p.name+"' must be an integer")
+		}
+		*p.value = n
+	}
+	return opts, nil
+}
+
+// respondPage writes a page of results, as a bare array or wrapped in an
+// Envelope when requested, in the negotiated format. item names each result
+// in XML and CSV.
+func respondPage[T any](h *Handler, c *fiber.Ctx, item, query string, page *service.Page[T], start time.Time) error {
+	if !wantsEnvelope(c) {
+		return respond(c, item, page.Items)
+	}
+
+	version, err := h.svc.DatasetVersion(c.UserContext())
+	if err != nil {
+		return Problem(c, err)
+	}
+	data := page.Items
+	if data == nil {
+		data = []T{}
+	}
+	return respond(c, item, Envelope{
+		Data: data,
+		Meta: Meta{
+			Query:           query,
+			NormalizedQuery: page.Query,
+			Expansions:      page.Expansions,
+			Total:           page.Total,
+			Limit:           page.Limit,
+			Offset:          page.Offset,
+			TookMs:          float64(time.Since(start).Microseconds()) / 1000,
+			DatasetVersion:  version,
+		},
+	})
+}
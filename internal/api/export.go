@@ -0,0 +1,269 @@
+package api
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+// exportTracerName is the instrumentation name of the export stream spans.
+const exportTracerName = "github.com/ilmimris/wilayah-indonesia/internal/api"
+
+// exportContentTypes maps the supported export formats to their content type.
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"ndjson": "application/x-ndjson",
+	"json":   fiber.MIMEApplicationJSONCharsetUTF8,
+}
+
+// ExportHandler handles the full-dataset export endpoint.
+// Rows are streamed from the database straight into the response body.
+func (h *Handler) ExportHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Extract and validate the format and level query parameters
+		format := c.Query("format", "json")
+		contentType, ok := exportContentTypes[format]
+		if !ok {
+			return Problem(c, service.NewFieldError("format", "Query parameter 'format' must be one of csv, ndjson or json"))
+		}
+		level := c.Query("level", service.LevelSubdistrict)
+		if !isExportLevel(level) {
+			return Problem(c, service.NewFieldError("level", "Query parameter 'level' must be one of "+strings.Join(service.ExportLevels, ", ")))
+		}
+
+		// The ETag is set by the cache middleware, but the body depends on the encoding
+		c.Vary(fiber.HeaderAcceptEncoding)
+
+		c.Set(fiber.HeaderContentType, contentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="regions-%s.%s"`, level, format))
+		gzipped := acceptsGzip(c.Get(fiber.HeaderAcceptEncoding))
+		if gzipped {
+			c.Set(fiber.HeaderContentEncoding, "gzip")
+		}
+
+		// The body is written after the handler returns, when c may be reused
+		// and the request span has ended, so the export has a span of its own
+		reqCtx := c.UserContext()
+		conn := c.Context().Conn()
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			ctx, span := startExportSpan(reqCtx, level, format)
+			defer span.End()
+
+			// The server set its write deadline just before the stream started
+			if h.exportWriteTimeout != nil {
+				var deadline time.Time
+				if *h.exportWriteTimeout > 0 {
+					deadline = time.Now().Add(*h.exportWriteTimeout)
+				}
+				if err := conn.SetWriteDeadline(deadline); err != nil {
+					slog.WarnContext(ctx, "Failed to set the export write deadline", "error", err)
+				}
+			}
+
+			var out io.Writer = w
+			var gz *gzip.Writer
+			if gzipped {
+				gz = gzip.NewWriter(w)
+				out = gz
+			}
+
+			enc := newExportEncoder(format, out)
+			err := h.svc.Export(ctx, level, enc.Write)
+			if err == nil {
+				err = enc.Close()
+			}
+			if err == nil && gz != nil {
+				err = gz.Close()
+			}
+			if err == nil {
+				err = w.Flush()
+			}
+			if err != nil {
+				// Headers are already sent, so the failure can only be logged
+				slog.ErrorContext(ctx, "Export stream failed", "error", err, "level", level, "format", format)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+		})
+		return nil
+	}
+}
+
+// startExportSpan starts the span of an export stream. The stream is written
+// after the request span has ended, so the span starts a trace of its own,
+// linked to the request span, rather than outliving its parent. Values of
+// ctx, such as the request ID, are kept.
+func startExportSpan(ctx context.Context, level, format string) (context.Context, trace.Span) {
+	return otel.Tracer(exportTracerName).Start(ctx, "Export stream",
+		trace.WithNewRoot(),
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+		trace.WithAttributes(service.AttrLevel.String(level), attribute.String("wilayah.export.format", format)),
+	)
+}
+
+// isExportLevel reports whether level is accepted by service.Export.
+func isExportLevel(level string) bool {
+	for _, l := range service.ExportLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// etagMatches reports whether an If-None-Match header matches etag using weak comparison.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows a gzip response.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		q := strings.ReplaceAll(params, " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
+
+// exportEncoder writes exported regions in one output format.
+type exportEncoder interface {
+	Write(region service.Region) error
+	Close() error
+}
+
+func newExportEncoder(format string, w io.Writer) exportEncoder {
+	switch format {
+	case "csv":
+		return &csvExportEncoder{w: csv.NewWriter(w)}
+	case "ndjson":
+		return &ndjsonExportEncoder{enc: json.NewEncoder(w)}
+	default:
+		return &jsonExportEncoder{w: w}
+	}
+}
+
+// exportColumns is the CSV header, matching the JSON field names of
+// service.Region, so that CSV exports carry the same columns as JSON. Stats
+// are empty where unset, as on district and subdistrict rows.
+var exportColumns = service.RegionFields
+
+// csvExportEncoder writes a header row followed by one row per region.
+type csvExportEncoder struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (e *csvExportEncoder) writeHeader() error {
+	if e.wroteHeader {
+		return nil
+	}
+	e.wroteHeader = true
+	return e.w.Write(exportColumns)
+}
+
+func (e *csvExportEncoder) Write(r service.Region) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.w.Write([]string{r.ID, r.Subdistrict, r.District, r.City, r.Province, r.PostalCode, r.FullText,
+		formatFloat(r.Latitude), formatFloat(r.Longitude), formatFloat(r.AreaKm2), formatInt(r.Population)})
+}
+
+// formatFloat formats an optional stat as a CSV value.
+func formatFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+// formatInt formats an optional stat as a CSV value.
+func formatInt(i *int64) string {
+	if i == nil {
+		return ""
+	}
+	return strconv.FormatInt(*i, 10)
+}
+
+func (e *csvExportEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ndjsonExportEncoder writes one JSON object per line.
+type ndjsonExportEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonExportEncoder) Write(r service.Region) error {
+	return e.enc.Encode(r)
+}
+
+func (e *ndjsonExportEncoder) Close() error {
+	return nil
+}
+
+// jsonExportEncoder writes a single JSON array one element at a time.
+type jsonExportEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonExportEncoder) Write(r service.Region) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	sep := ","
+	if e.count == 0 {
+		sep = "["
+	}
+	e.count++
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExportEncoder) Close() error {
+	end := "]"
+	if e.count == 0 {
+		end = "[]"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+// defaultV2Fields are the region fields of /v2 results without ?fields=.
+// They leave out full_text, a lowercase copy of the other fields that
+// roughly doubles the size of a response.
+var defaultV2Fields = slices.DeleteFunc(slices.Clone(service.RegionFields), func(field string) bool {
+	return field == service.FieldFullText
+})
+
+// regionFields returns the region fields selected with the fields query
+// parameter, in the requested order. Without it, /v2 endpoints select
+// defaultV2Fields and others select every field, reported as nil.
+func regionFields(c *fiber.Ctx) []string {
+	fields := parseInclude(c.Query("fields"))
+	if len(fields) == 0 {
+		if enveloped, _ := c.Locals(envelopeKey).(bool); enveloped {
+			return defaultV2Fields
+		}
+		return nil
+	}
+	var unique []string
+	for _, field := range fields {
+		if !slices.Contains(unique, field) {
+			unique = append(unique, field)
+		}
+	}
+	return unique
+}
+
+// record is a result holding only some of its fields, written in order.
+// respond encodes it like a struct in every format.
+type record []recordField
+
+// recordField is one field of a record.
+type recordField struct {
+	name  string
+	value interface{}
+
+	// omitEmpty leaves a nil value out of JSON, MessagePack and XML, like
+	// the omitempty option of a struct field. CSV still gets its column.
+	omitEmpty bool
+}
+
+// omitted reports whether the field is left out of JSON and MessagePack.
+func (f recordField) omitted() bool {
+	return f.omitEmpty && f.value == nil
+}
+
+// MarshalJSON writes the record as a JSON object.
+func (r record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for _, f := range r {
+		if f.omitted() {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		name, err := json.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// EncodeMsgpack writes the record as a MessagePack map.
+func (r record) EncodeMsgpack(enc *msgpack.Encoder) error {
+	fields := slices.DeleteFunc(slices.Clone(r), recordField.omitted)
+	if err := enc.EncodeMapLen(len(fields)); err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if err := enc.EncodeString(f.name); err != nil {
+			return err
+		}
+		if err := enc.Encode(f.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// projectRegion returns the selected fields of a region as a record. As in
+// its JSON, unset stats are left out, but they keep an empty CSV column so
+// that the columns do not depend on the results. The attributes follow the
+// fields when requested with ?include=.
+func projectRegion(r service.Region, fields []string) record {
+	rec := make(record, 0, len(fields)+1)
+	for _, field := range fields {
+		var value interface{}
+		omitEmpty := false
+		switch field {
+		case service.FieldID:
+			value = r.ID
+		case service.FieldSubdistrict:
+			value = r.Subdistrict
+		case service.FieldDistrict:
+			value = r.District
+		case service.FieldCity:
+			value = r.City
+		case service.FieldProvince:
+			value = r.Province
+		case service.FieldPostalCode:
+			value = r.PostalCode
+		case service.FieldFullText:
+			value = r.FullText
+		case service.FieldLatitude:
+			omitEmpty = true
+			if r.Latitude != nil {
+				value = *r.Latitude
+			}
+		case service.FieldLongitude:
+			omitEmpty = true
+			if r.Longitude != nil {
+				value = *r.Longitude
+			}
+		case service.FieldAreaKm2:
+			omitEmpty = true
+			if r.AreaKm2 != nil {
+				value = *r.AreaKm2
+			}
+		case service.FieldPopulation:
+			omitEmpty = true
+			if r.Population != nil {
+				value = *r.Population
+			}
+		}
+		rec = append(rec, recordField{name: field, value: value, omitEmpty: omitEmpty})
+	}
+	if r.Attributes != nil {
+		rec = append(rec, recordField{name: "attributes", value: r.Attributes})
+	}
+	return rec
+}
+
+// projectPage returns a page of regions as records of the selected fields.
+func projectPage(page *service.Page[service.Region], fields []string) *service.Page[record] {
+	projected := &service.Page[record]{
+		Total:      page.Total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		Query:      page.Query,
+		Expansions: page.Expansions,
+	}
+	for _, r := range page.Items {
+		projected.Items = append(projected.Items, projectRegion(r, fields))
+	}
+	return projected
+}
@@ -0,0 +1,369 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+// Response formats of the search and lookup endpoints, selected by the
+// format query parameter or the Accept header.
+const (
+	FormatJSON    = "json"
+	FormatCSV     = "csv"
+	FormatXML     = "xml"
+	FormatMsgPack = "msgpack"
+)
+
+// HeaderTotalCount carries the total number of matches of an enveloped
+// search answered as CSV, which cannot hold the envelope.
+const HeaderTotalCount = "X-Total-Count"
+
+// formatContentTypes maps the response formats to their content type.
+var formatContentTypes = map[string]string{
+	FormatJSON:    fiber.MIMEApplicationJSONCharsetUTF8,
+	FormatCSV:     "text/csv; charset=utf-8",
+	FormatXML:     fiber.MIMEApplicationXMLCharsetUTF8,
+	FormatMsgPack: "application/msgpack",
+}
+
+// acceptedTypes maps the media types accepted in the Accept header to the
+// response formats, in order of preference.
+var acceptedTypes = []struct {
+	mediaType string
+	format    string
+}{
+	{fiber.MIMEApplicationJSON, FormatJSON},
+	{"text/csv", FormatCSV},
+	{fiber.MIMEApplicationXML, FormatXML},
+	{fiber.MIMETextXML, FormatXML},
+	{"application/msgpack", FormatMsgPack},
+	{"application/x-msgpack", FormatMsgPack},
+	{"application/vnd.msgpack", FormatMsgPack},
+}
+
+// responseFormat returns the format requested with the format query
+// parameter, or else the best format accepted by the Accept header. Clients
+// accepting none of the formats get JSON, as before formats were negotiated.
+func responseFormat(c *fiber.Ctx) (string, error) {
+	if format := c.Query("format"); format != "" {
+		if _, ok := formatContentTypes[format]; !ok {
+			return "", service.NewFieldError("format", "Query parameter 'format' must be one of json, csv, xml or msgpack")
+		}
+		return format, nil
+	}
+
+	offers := make([]string, len(acceptedTypes))
+	for i, t := range acceptedTypes {
+		offers[i] = t.mediaType
+	}
+	accepted := c.Accepts(offers...)
+	for _, t := range acceptedTypes {
+		if t.mediaType == accepted {
+			return t.format, nil
+		}
+	}
+	return FormatJSON, nil
+}
+
+// respond writes v in the negotiated format. In XML and CSV, item names the
+// elements or rows of v, or of its items when v is a list or an Envelope.
+func respond(c *fiber.Ctx, item string, v interface{}) error {
+	c.Vary(fiber.HeaderAccept)
+	format, err := responseFormat(c)
+	if err != nil {
+		return Problem(c, err)
+	}
+	if format == FormatJSON {
+		return c.JSON(v)
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatCSV:
+		if envelope, ok := v.(Envelope); ok {
+			c.Set(HeaderTotalCount, strconv.Itoa(envelope.Meta.Total))
+			v = envelope.Data
+		}
+		err = encodeCSV(&buf, item, v)
+	case FormatXML:
+		err = encodeXML(&buf, item, v)
+	case FormatMsgPack:
+		enc := msgpack.NewEncoder(&buf)
+		enc.SetCustomStructTag("json")
+		err = enc.Encode(v)
+	}
+	if err != nil {
+		return Problem(c, fmt.Errorf("failed to encode the response as %s: %w", format, err))
+	}
+	c.Set(fiber.HeaderContentType, formatContentTypes[format])
+	return c.Send(buf.Bytes())
+}
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+	recordType     = reflect.TypeOf(record(nil))
+)
+
+// isList reports whether v is a list of values rather than a single value
+// stored as a slice.
+func isList(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type() != rawMessageType && v.Type() != recordType
+}
+
+// jsonFields returns the exported fields of a struct type with the names
+// they have in JSON, skipping those excluded from it.
+func jsonFields(t reflect.Type) (names []string, indexes []int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+		indexes = append(indexes, i)
+	}
+	return names, indexes
+}
+
+// scalarString formats a scalar value, and reports false for other kinds.
+func scalarString(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+	}
+	if v.Type() == rawMessageType {
+		return string(v.Bytes()), true
+	}
+	return "", false
+}
+
+// indirect follows pointers and interfaces, and reports false for nil.
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
+
+// sortedKeys returns the keys of a map value in order.
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(fmt.Sprint(a), fmt.Sprint(b)) })
+	return keys
+}
+
+// encodeCSV writes v, a value or a list of values, as CSV with one row per
+// value. Struct and record fields become columns named as in JSON, maps such as the
+// attributes are flattened into dotted columns, and other nested values are
+// written as JSON. Lists of scalars get a single column named item.
+func encodeCSV(buf *bytes.Buffer, item string, v interface{}) error {
+	rv, ok := indirect(reflect.ValueOf(v))
+	var rows []reflect.Value
+	switch {
+	case !ok:
+	case isList(rv):
+		for i := 0; i < rv.Len(); i++ {
+			rows = append(rows, rv.Index(i))
+		}
+	default:
+		rows = []reflect.Value{rv}
+	}
+
+	// Rows may have different attributes, so collect every column first
+	var columns []string
+	seen := make(map[string]bool)
+	records := make([]map[string]string, len(rows))
+	for i, row := range rows {
+		records[i] = make(map[string]string)
+		add := func(column, value string) {
+			if column == "" {
+				column = item
+			}
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+			records[i][column] = value
+		}
+		if err := flattenCSV("", row, add); err != nil {
+			return err
+		}
+	}
+
+	w := csv.NewWriter(buf)
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, values := range records {
+		for i, column := range columns {
+			record[i] = values[column]
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// flattenCSV passes the columns of v, named after prefix, to add. Struct
+// fields always get a column, even when nil, so that the columns of a
+// response do not depend on which results it holds.
+func flattenCSV(prefix string, v reflect.Value, add func(column, value string)) error {
+	join := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+
+	v, ok := indirect(v)
+	if !ok {
+		add(prefix, "")
+		return nil
+	}
+	if s, ok := scalarString(v); ok {
+		add(prefix, s)
+		return nil
+	}
+
+	if rec, ok := v.Interface().(record); ok {
+		for _, f := range rec {
+			if err := flattenCSV(join(f.name), reflect.ValueOf(f.value), add); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		names, indexes := jsonFields(v.Type())
+		for i, name := range names {
+			if err := flattenCSV(join(name), v.Field(indexes[i]), add); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range sortedKeys(v) {
+			if err := flattenCSV(join(fmt.Sprint(key)), v.MapIndex(key), add); err != nil {
+				return err
+			}
+		}
+	default:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return err
+		}
+		add(prefix, string(data))
+	}
+	return nil
+}
+
+// encodeXML writes v as XML. Lists are wrapped in a results element, with
+// one element named item per value, and single values are written as an
+// item element. Struct fields become elements named as in JSON, with list
+// values named by their xml tag, if any. Nil fields, maps and lists are left
+// out, and map entries become entry elements with a key attribute.
+func encodeXML(buf *bytes.Buffer, item string, v interface{}) error {
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(buf)
+
+	name := item
+	if rv, ok := indirect(reflect.ValueOf(v)); ok {
+		switch {
+		case isList(rv):
+			name = "results"
+		case rv.Type() == reflect.TypeOf(Envelope{}):
+			name = "response"
+		}
+	}
+	if err := writeXML(enc, xml.StartElement{Name: xml.Name{Local: name}}, item, reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// writeXML writes v as the element start. The values of lists are written
+// as elements named item.
+func writeXML(enc *xml.Encoder, start xml.StartElement, item string, v reflect.Value) error {
+	v, ok := indirect(v)
+	if !ok || ((v.Kind() == reflect.Map || v.Kind() == reflect.Slice) && v.IsNil()) {
+		return nil
+	}
+	if s, ok := scalarString(v); ok {
+		return enc.EncodeElement(s, start)
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch rec, isRecord := v.Interface().(record); {
+	case isRecord:
+		for _, f := range rec {
+			if err := writeXML(enc, xml.StartElement{Name: xml.Name{Local: f.name}}, item, reflect.ValueOf(f.value)); err != nil {
+				return err
+			}
+		}
+	case v.Kind() == reflect.Struct:
+		names, indexes := jsonFields(v.Type())
+		for i, name := range names {
+			// The xml tag of a list field names its values
+			fieldItem := item
+			if tag := v.Type().Field(indexes[i]).Tag.Get("xml"); tag != "" {
+				fieldItem = tag
+			}
+			if err := writeXML(enc, xml.StartElement{Name: xml.Name{Local: name}}, fieldItem, v.Field(indexes[i])); err != nil {
+				return err
+			}
+		}
+	case v.Kind() == reflect.Map:
+		for _, key := range sortedKeys(v) {
+			entry := xml.StartElement{
+				Name: xml.Name{Local: "entry"},
+				Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: fmt.Sprint(key)}},
+			}
+			if err := writeXML(enc, entry, item, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := writeXML(enc, xml.StartElement{Name: xml.Name{Local: item}}, item, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot encode %s as XML", v.Type())
+	}
+	return enc.EncodeToken(start.End())
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+func testRegions() []service.Region {
+	population := int64(2500000)
+	return []service.Region{
+		{ID: "32.73", City: "Kota Bandung", Province: "Jawa Barat", Population: &population,
+			Attributes: map[string]map[string]interface{}{"bps": {"code": "3273"}}},
+		{ID: "32.73.01.1001", Subdistrict: "Sarijadi, Utara", District: "Sukasari", City: "Kota Bandung", Province: "Jawa Barat"},
+	}
+}
+
+func TestEncodeCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeCSV(&buf, "region", testRegions()); err != nil {
+		t.Fatalf("encodeCSV returned error: %v", err)
+	}
+	want := "id,subdistrict,district,city,province,postal_code,full_text,latitude,longitude,area_km2,population,attributes.bps.code\n" +
+		"32.73,,,Kota Bandung,Jawa Barat,,,,,,2500000,3273\n" +
+		"32.73.01.1001,\"Sarijadi, Utara\",Sukasari,Kota Bandung,Jawa Barat,,,,,,,\n"
+	if buf.String() != want {
+		t.Errorf("unexpected CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := encodeCSV(&buf, "attribute_set", []string{"bps", "kemendagri"}); err != nil {
+		t.Fatalf("encodeCSV returned error: %v", err)
+	}
+	if buf.String() != "attribute_set\nbps\nkemendagri\n" {
+		t.Errorf("unexpected CSV for a list of strings:\n%s", buf.String())
+	}
+}
+
+func TestEncodeXML(t *testing.T) {
+	var buf bytes.Buffer
+	meta := Meta{Query: "bandung", NormalizedQuery: "bandung", Expansions: []string{"Kota bandung", "Kabupaten bandung"}, Total: 1}
+	if err := encodeXML(&buf, "region", Envelope{Data: testRegions()[:1], Meta: meta}); err != nil {
+		t.Fatalf("encodeXML returned error: %v", err)
+	}
+	for _, part := range []string{
+		"<response><data><region><id>32.73</id>",
+		"<population>2500000</population>",
+		`<attributes><entry key="bps"><entry key="code">3273</entry></entry></attributes></region></data>`,
+		"<meta><query>bandung</query><normalized_query>bandung</normalized_query>",
+		"<expansions><expansion>Kota bandung</expansion><expansion>Kabupaten bandung</expansion></expansions><total>1</total>",
+	} {
+		if !strings.Contains(buf.String(), part) {
+			t.Errorf("expected XML to contain %s, got:\n%s", part, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "<latitude>") {
+		t.Errorf("expected nil fields to be left out, got:\n%s", buf.String())
+	}
+}
+
+func TestRespondNegotiation(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/regions", func(c *fiber.Ctx) error {
+		return respond(c, "region", testRegions())
+	})
+
+	tests := []struct {
+		target      string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{target: "/regions", contentType: fiber.MIMEApplicationJSON},
+		{target: "/regions", accept: "text/html, */*;q=0.8", contentType: fiber.MIMEApplicationJSON},
+		{target: "/regions", accept: "text/plain", contentType: fiber.MIMEApplicationJSON},
+		{target: "/regions", accept: "text/csv", contentType: "text/csv"},
+		{target: "/regions", accept: "application/xml;q=0.9, application/json;q=0.5", contentType: fiber.MIMEApplicationXML},
+		{target: "/regions?format=msgpack", accept: "application/json", contentType: "application/msgpack"},
+		{target: "/regions?format=yaml", status: fiber.StatusBadRequest, contentType: "application/problem+json"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.target, nil)
+		if tt.accept != "" {
+			req.Header.Set(fiber.HeaderAccept, tt.accept)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		status := tt.status
+		if status == 0 {
+			status = fiber.StatusOK
+		}
+		if resp.StatusCode != status || !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), tt.contentType) {
+			t.Errorf("%s with Accept %q: got %d %s, want %d %s", tt.target, tt.accept,
+				resp.StatusCode, resp.Header.Get(fiber.HeaderContentType), status, tt.contentType)
+		}
+
+		if tt.contentType == "application/msgpack" {
+			body, _ := io.ReadAll(resp.Body)
+			var decoded []map[string]interface{}
+			if err := msgpack.Unmarshal(body, &decoded); err != nil || len(decoded) != 2 || decoded[0]["city"] != "Kota Bandung" {
+				t.Errorf("unexpected MessagePack body %v, %v", decoded, err)
+			}
+		}
+	}
+}
+
+func TestProjectRegion(t *testing.T) {
+	rec := projectRegion(testRegions()[0], []string{"population", "id", "latitude"})
+	data, err := rec.MarshalJSON()
+	if err != nil || string(data) != `{"population":2500000,"id":"32.73","attributes":{"bps":{"code":"3273"}}}` {
+		t.Errorf("unexpected JSON %s, %v", data, err)
+	}
+
+	packed, err := msgpack.Marshal(rec)
+	if err != nil {
+		t.Fatalf("failed to encode the record as MessagePack: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := msgpack.Unmarshal(packed, &decoded); err != nil || len(decoded) != 3 || decoded["id"] != "32.73" {
+		t.Errorf("unexpected MessagePack record %v, %v", decoded, err)
+	}
+}
@@ -0,0 +1,17 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/graphql"
+)
+
+// GraphQLHandler handles the GraphQL endpoint. The schema is embedded in the
+// binary, so a parse failure is a programming error and panics at startup.
+func (h *Handler) GraphQLHandler() fiber.Handler {
+	schema, err := graphql.NewSchema(h.svc)
+	if err != nil {
+		panic("invalid GraphQL schema: " + err.Error())
+	}
+	return graphql.Handler(schema)
+}
@@ -3,241 +3,314 @@ package api
 import (
 	"database/sql"
 	"log/slog"
+	"sync/atomic"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/ilmimris/wilayah-indonesia/internal/auth"
+	"github.com/ilmimris/wilayah-indonesia/internal/buildinfo"
+	"github.com/ilmimris/wilayah-indonesia/internal/ratelimit"
 	"github.com/ilmimris/wilayah-indonesia/pkg/service"
 )
 
 // Handler wraps the service to provide HTTP handlers.
 type Handler struct {
-	svc *service.Service
+	svc            *service.Service
+	limiter        *ratelimit.Limiter
+	keyring        *auth.Keyring
+	protectMetrics bool
+	cacheMaxAge    map[string]time.Duration
+
+	// exportWriteTimeout replaces the server write timeout for export
+	// streams when set.
+	exportWriteTimeout *time.Duration
+
+	// ready is cleared when the server starts draining on shutdown.
+	ready atomic.Bool
+
+	// started is when the handler was created, reported as the uptime.
+	started time.Time
+}
+
+// Option configures optional behaviour of a Handler.
+type Option func(*Handler)
+
+// WithRateLimiter limits the request rate of each client on the search,
+// export and batch routes.
+func WithRateLimiter(l *ratelimit.Limiter) Option {
+	return func(h *Handler) {
+		h.limiter = l
+	}
+}
+
+// WithKeyring requires an API key from the keyring on every route except the
+// health check, the metrics and the documentation.
+func WithKeyring(k *auth.Keyring) Option {
+	return func(h *Handler) {
+		h.keyring = k
+	}
+}
+
+// WithProtectedMetrics requires an API key with the admin scope on the
+// metrics endpoint. It has no effect without WithKeyring.
+func WithProtectedMetrics() Option {
+	return func(h *Handler) {
+		h.protectMetrics = true
+	}
+}
+
+// WithExportWriteTimeout bounds the time to write an export to timeout
+// instead of the server write timeout, which is sized for other responses
+// and would cut off exports to slow clients. A zero timeout removes the limit.
+func WithExportWriteTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.exportWriteTimeout = &timeout
+	}
 }
 
 // New creates a new Handler instance with the provided service.
-func New(svc *service.Service) *Handler {
-	return &Handler{
-		svc: svc,
+func New(svc *service.Service, opts ...Option) *Handler {
+	h := &Handler{
+		svc:         svc,
+		cacheMaxAge: make(map[string]time.Duration),
+		started:     time.Now(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.ready.Store(true)
+	return h
+}
+
+// SetReady sets whether the health check reports the service as able to take
+// traffic. The server clears it before draining on shutdown, so that load
+// balancers stop routing new requests while in-flight ones finish.
+func (h *Handler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// authorize returns the middleware requiring an API key granted scope, which
+// lets every request through when no keyring is configured.
+func (h *Handler) authorize(scope string) fiber.Handler {
+	if h.keyring == nil {
+		return passThrough
 	}
+	return h.keyring.Middleware(scope)
+}
+
+// metricsAuth returns the middleware guarding the metrics endpoint.
+func (h *Handler) metricsAuth() fiber.Handler {
+	if !h.protectMetrics {
+		return passThrough
+	}
+	return h.authorize(auth.ScopeAdmin)
+}
+
+// rateLimit returns the middleware limiting the routes of group, which lets
+// every request through when no limiter is configured.
+func (h *Handler) rateLimit(group string) fiber.Handler {
+	if h.limiter == nil {
+		return passThrough
+	}
+	return h.limiter.Middleware(group)
+}
+
+// passThrough is a middleware doing nothing.
+func passThrough(c *fiber.Ctx) error {
+	return c.Next()
 }
 
 // SearchHandler handles the search endpoint
 func (h *Handler) SearchHandler() fiber.Handler {
-	return func(c *fiber.Ctx) error {
+	return h.searchHandler(service.SearchAll, func(c *fiber.Ctx) (string, error) {
 		// Extract and validate the q query parameter
 		query := c.Query("q")
 		if query == "" {
-			slog.Warn("Search query parameter missing", "ip", c.IP())
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Query parameter 'q' is required",
-			})
+			slog.WarnContext(c.UserContext(), "Search query parameter missing", "ip", c.IP())
+			return "", service.NewFieldError("q", "Query parameter 'q' is required")
 		}
-
-		// Use the service to perform the search
-		results, err := h.svc.Search(query)
-		if err != nil {
-			if service.IsError(err, service.ErrCodeInvalidInput) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": err.Error(),
-				})
-			}
-			if service.IsError(err, service.ErrCodeDatabaseFailure) {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Database query failed",
-				})
-			}
-			// Default to internal server error for any other errors
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-
-		// Return JSON response
-		return c.JSON(results)
-	}
+		return query, nil
+	})
 }
 
 // DistrictSearchHandler handles the district search endpoint
 func (h *Handler) DistrictSearchHandler() fiber.Handler {
-	return func(c *fiber.Ctx) error {
+	return h.searchHandler(service.SearchDistrict, func(c *fiber.Ctx) (string, error) {
 		// Extract and validate the q query parameter
 		query := c.Query("q")
 		if query == "" {
-			slog.Warn("District search query parameter missing", "ip", c.IP())
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Query parameter 'q' is required",
-			})
+			slog.WarnContext(c.UserContext(), "District search query parameter missing", "ip", c.IP())
+			return "", service.NewFieldError("q", "Query parameter 'q' is required")
 		}
-
-		// Use the service to perform the search
-		results, err := h.svc.SearchByDistrict(query)
-		if err != nil {
-			if service.IsError(err, service.ErrCodeInvalidInput) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": err.Error(),
-				})
-			}
-			if service.IsError(err, service.ErrCodeDatabaseFailure) {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Database query failed",
-				})
-			}
-			// Default to internal server error for any other errors
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-
-		// Return JSON response
-		return c.JSON(results)
-	}
+		return query, nil
+	})
 }
 
 // SubdistrictSearchHandler handles the subdistrict search endpoint
 func (h *Handler) SubdistrictSearchHandler() fiber.Handler {
-	return func(c *fiber.Ctx) error {
+	return h.searchHandler(service.SearchSubdistrict, func(c *fiber.Ctx) (string, error) {
 		// Extract and validate the q query parameter
 		query := c.Query("q")
 		if query == "" {
-			slog.Warn("Subdistrict search query parameter missing", "ip", c.IP())
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Query parameter 'q' is required",
-			})
+			slog.WarnContext(c.UserContext(), "Subdistrict search query parameter missing", "ip", c.IP())
+			return "", service.NewFieldError("q", "Query parameter 'q' is required")
 		}
-
-		// Use the service to perform the search
-		results, err := h.svc.SearchBySubdistrict(query)
-		if err != nil {
-			if service.IsError(err, service.ErrCodeInvalidInput) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": err.Error(),
-				})
-			}
-			if service.IsError(err, service.ErrCodeDatabaseFailure) {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Database query failed",
-				})
-			}
-			// Default to internal server error for any other errors
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-
-		// Return JSON response
-		return c.JSON(results)
-	}
+		return query, nil
+	})
 }
 
 // CitySearchHandler handles the city search endpoint
 func (h *Handler) CitySearchHandler() fiber.Handler {
-	return func(c *fiber.Ctx) error {
+	return h.searchHandler(service.SearchCity, func(c *fiber.Ctx) (string, error) {
 		// Extract and validate the q query parameter
 		query := c.Query("q")
 		if query == "" {
-			slog.Warn("City search query parameter missing", "ip", c.IP())
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Query parameter 'q' is required",
-			})
+			slog.WarnContext(c.UserContext(), "City search query parameter missing", "ip", c.IP())
+			return "", service.NewFieldError("q", "Query parameter 'q' is required")
 		}
+		return query, nil
+	})
+}
 
-		// Use the service to perform the search
-		results, err := h.svc.SearchByCity(query)
+// ProvinceSearchHandler handles the province search endpoint
+func (h *Handler) ProvinceSearchHandler() fiber.Handler {
+	return h.searchHandler(service.SearchProvince, func(c *fiber.Ctx) (string, error) {
+		// Extract and validate the q query parameter
+		query := c.Query("q")
+		if query == "" {
+			slog.WarnContext(c.UserContext(), "Province search query parameter missing", "ip", c.IP())
+			return "", service.NewFieldError("q", "Query parameter 'q' is required")
+		}
+		return query, nil
+	})
+}
+
+// PostalCodeSearchHandler handles the postal code search endpoint
+func (h *Handler) PostalCodeSearchHandler() fiber.Handler {
+	return h.searchHandler(service.SearchPostalCode, func(c *fiber.Ctx) (string, error) {
+		// Extract and validate the postal code from path parameter
+		postalCode := c.Params("postalCode")
+		if postalCode == "" {
+			slog.WarnContext(c.UserContext(), "Postal code parameter missing", "ip", c.IP())
+			return "", service.NewFieldError("postalCode", "Postal code parameter is required")
+		}
+		return postalCode, nil
+	})
+}
+
+// searchHandler builds the handler of a search endpoint. param extracts and
+// validates the search term from the request.
+func (h *Handler) searchHandler(kind string, param func(c *fiber.Ctx) (string, error)) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		query, err := param(c)
 		if err != nil {
-			if service.IsError(err, service.ErrCodeInvalidInput) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": err.Error(),
-				})
-			}
-			if service.IsError(err, service.ErrCodeDatabaseFailure) {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Database query failed",
-				})
-			}
-			// Default to internal server error for any other errors
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return Problem(c, err)
 		}
+		opts, err := searchOptions(c)
+		if err != nil {
+			return Problem(c, err)
+		}
+		opts.Fields = regionFields(c)
 
-		// Return JSON response
-		return c.JSON(results)
+		// Use the service to perform the search and attach the requested attributes
+		page, err := h.svc.SearchPage(c.UserContext(), kind, query, opts)
+		if err == nil {
+			err = h.svc.WithAttributes(c.UserContext(), page.Items, parseInclude(c.Query("include")))
+		}
+		if err != nil {
+			return Problem(c, err)
+		}
+
+		// Return the page, with only the selected fields, in the negotiated format
+		if opts.Fields != nil {
+			return respondPage(h, c, "region", query, projectPage(page, opts.Fields), start)
+		}
+		return respondPage(h, c, "region", query, page, start)
 	}
 }
 
-// ProvinceSearchHandler handles the province search endpoint
-func (h *Handler) ProvinceSearchHandler() fiber.Handler {
+// HealthHandler handles the health check endpoint
+func (h *Handler) HealthHandler() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Extract and validate the q query parameter
-		query := c.Query("q")
-		if query == "" {
-			slog.Warn("Province search query parameter missing", "ip", c.IP())
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Query parameter 'q' is required",
+		// Report the server as unavailable while it drains
+		if !h.ready.Load() {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"status":  "unavailable",
+				"message": "Service is shutting down",
 			})
 		}
 
-		// Use the service to perform the search
-		results, err := h.svc.SearchByProvince(query)
-		if err != nil {
-			if service.IsError(err, service.ErrCodeInvalidInput) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": err.Error(),
-				})
-			}
-			if service.IsError(err, service.ErrCodeDatabaseFailure) {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Database query failed",
-				})
-			}
-			// Default to internal server error for any other errors
+		// Check database connection
+		if err := h.svc.Ping(c.UserContext()); err != nil {
+			slog.ErrorContext(c.UserContext(), "Database connection failed in health check", "error", err)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": err.Error(),
+				"status":  "error",
+				"message": "Database connection failed",
 			})
 		}
+		return c.JSON(fiber.Map{
+			"status":  "ok",
+			"message": "Service is healthy",
+		})
+	}
+}
 
-		// Return JSON response
-		return c.JSON(results)
+// LivezHandler handles the liveness probe. It only reports that the process
+// serves requests, so that a slow or broken database never gets the
+// container restarted; readiness covers the dataset.
+func (h *Handler) LivezHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status":         "ok",
+			"uptime_seconds": time.Since(h.started).Seconds(),
+		})
 	}
 }
 
-// PostalCodeSearchHandler handles the postal code search endpoint
-func (h *Handler) PostalCodeSearchHandler() fiber.Handler {
+// ReadyzHandler handles the readiness probe. It checks the schema, the row
+// counts and canary full-text and Jaro-Winkler queries, and reports the
+// dataset, the uptime and the build of the server. It fails while the server
+// drains on shutdown or when any check fails.
+func (h *Handler) ReadyzHandler() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Extract and validate the postal code from path parameter
-		postalCode := c.Params("postalCode")
-		if postalCode == "" {
-			slog.Warn("Postal code parameter missing", "ip", c.IP())
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Postal code parameter is required",
+		// Report the server as unavailable while it drains
+		if !h.ready.Load() {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"status":  "unavailable",
+				"message": "Service is shutting down",
 			})
 		}
 
-		// Use the service to perform the search
-		results, err := h.svc.SearchByPostalCode(postalCode)
-		if err != nil {
-			if service.IsError(err, service.ErrCodeInvalidInput) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": err.Error(),
-				})
-			}
-			if service.IsError(err, service.ErrCodeNotFound) {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-					"error": err.Error(),
-				})
+		readiness := h.svc.Readiness(c.UserContext())
+		checks := make([]fiber.Map, 0, len(readiness.Checks))
+		for _, check := range readiness.Checks {
+			status := "ok"
+			if check.Err != nil {
+				status = "failed"
 			}
-			if service.IsError(err, service.ErrCodeDatabaseFailure) {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Database query failed",
-				})
-			}
-			// Default to internal server error for any other errors
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": err.Error(),
+			checks = append(checks, fiber.Map{
+				"name":        check.Name,
+				"status":      status,
+				"duration_ms": float64(check.Duration.Microseconds()) / 1000,
 			})
 		}
 
-		// Return JSON response
-		return c.JSON(results)
+		status, code := "ok", fiber.StatusOK
+		if !readiness.Ready {
+			status, code = "unavailable", fiber.StatusServiceUnavailable
+		}
+		return c.Status(code).JSON(fiber.Map{
+			"status": status,
+			"checks": checks,
+			"dataset": fiber.Map{
+				"version":    readiness.DatasetVersion,
+				"row_counts": readiness.RowCounts,
+			},
+			"checked_at":     readiness.CheckedAt.UTC().Format(time.RFC3339),
+			"uptime_seconds": time.Since(h.started).Seconds(),
+			"build":          buildinfo.Get(),
+		})
 	}
 }
 
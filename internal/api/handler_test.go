@@ -0,0 +1,326 @@
+package api
+
+import (
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	_ "github.com/marcboeker/go-duckdb"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+func TestHealthHandlerDrain(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	h := New(service.New(db))
+	app := fiber.New()
+	app.Get("/healthz", h.HealthHandler())
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/healthz", nil))
+	if err != nil || resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected a healthy service, got %v, %v", resp, err)
+	}
+
+	h.SetReady(false)
+	resp, err = app.Test(httptest.NewRequest("GET", "/healthz", nil))
+	if err != nil || resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("expected 503 while draining, got %v, %v", resp, err)
+	}
+}
+
+func TestProbes(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	h := New(service.New(db))
+	app := fiber.New()
+	app.Get("/livez", h.LivezHandler())
+	app.Get("/readyz", h.ReadyzHandler())
+
+	// Liveness does not depend on the dataset, which is missing here
+	resp, err := app.Test(httptest.NewRequest("GET", "/livez", nil))
+	if err != nil || resp.StatusCode != fiber.StatusOK {
+		t.Errorf("expected a live service, got %v, %v", resp, err)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/readyz", nil))
+	if err != nil || resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a regions table, got %v, %v", resp, err)
+	}
+	var body struct {
+		Status string `json:"status"`
+		Checks []struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+		} `json:"checks"`
+		Build struct {
+			Version string `json:"version"`
+		} `json:"build"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode readiness: %v", err)
+	}
+	if body.Status != "unavailable" || len(body.Checks) == 0 || body.Checks[0].Status != "failed" || body.Build.Version == "" {
+		t.Errorf("unexpected readiness %+v", body)
+	}
+}
+
+func TestHTTPCache(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`
+		CREATE TABLE regions (id VARCHAR, subdistrict VARCHAR, district VARCHAR, city VARCHAR,
+			province VARCHAR, postal_code VARCHAR, full_text VARCHAR);
+		INSERT INTO regions VALUES ('32.73.01.1001', 'Sarijadi', 'Sukasari', 'Kota Bandung', 'Jawa Barat', '40151', '');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create regions: %v", err)
+	}
+
+	h := New(service.New(db), WithCacheMaxAge(CacheSearch, 5*time.Minute))
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/v1/search/province", h.httpCache(CacheSearch), h.ProvinceSearchHandler())
+
+	get := func(target, ifNoneMatch string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("GET", target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := get("/v1/search/province?q=Jawa+Barat&limit=5", "")
+	etag := resp.Header.Get(fiber.HeaderETag)
+	if resp.StatusCode != fiber.StatusOK || !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("expected 200 with a weak ETag, got %d and %q", resp.StatusCode, etag)
+	}
+	if cc := resp.Header.Get(fiber.HeaderCacheControl); cc != "public, max-age=300" {
+		t.Errorf("unexpected Cache-Control %q", cc)
+	}
+
+	// The order of the query parameters does not matter
+	if resp := get("/v1/search/province?limit=5&q=Jawa+Barat", etag); resp.StatusCode != fiber.StatusNotModified {
+		t.Errorf("expected 304 for a matching ETag, got %d", resp.StatusCode)
+	}
+	if resp := get("/v1/search/province?q=Jawa+Barat&limit=6", etag); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("expected 200 for other parameters, got %d", resp.StatusCode)
+	}
+
+	// Errors are not cacheable
+	resp = get("/v1/search/province", "")
+	if resp.StatusCode != fiber.StatusBadRequest || resp.Header.Get(fiber.HeaderETag) != "" {
+		t.Errorf("expected 400 without an ETag, got %d and %q", resp.StatusCode, resp.Header.Get(fiber.HeaderETag))
+	}
+}
+
+func TestSearchFields(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`
+		CREATE TABLE regions (id VARCHAR, subdistrict VARCHAR, district VARCHAR, city VARCHAR,
+			province VARCHAR, postal_code VARCHAR, full_text VARCHAR);
+		INSERT INTO regions VALUES ('32.73.01.1001', 'Sarijadi', 'Sukasari', 'Kota Bandung', 'Jawa Barat', '40151',
+			'jawa barat kota bandung sukasari sarijadi');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create regions: %v", err)
+	}
+
+	h := New(service.New(db))
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/v1/search/province", h.ProvinceSearchHandler())
+	app.Get("/v1/regions/:code", h.RegionHandler())
+	app.Group("/v2", UseEnvelope()).Get("/search/province", h.ProvinceSearchHandler())
+
+	tests := []struct {
+		target string
+		status int
+		want   string
+	}{
+		{target: "/v1/search/province?q=Jawa+Barat", want: `"full_text":"jawa barat kota bandung sukasari sarijadi"`},
+		{target: "/v1/search/province?q=Jawa+Barat&fields=id,city,postal_code", want: `[{"id":"32.73.01.1001","city":"Kota Bandung","postal_code":"40151"}]`},
+		{target: "/v2/search/province?q=Jawa+Barat", want: `"data":[{"id":"32.73.01.1001","subdistrict":"Sarijadi","district":"Sukasari","city":"Kota Bandung","province":"Jawa Barat","postal_code":"40151"}]`},
+		{target: "/v2/search/province?q=Jawa+Barat&fields=postal_code,full_text", want: `"data":[{"postal_code":"40151","full_text":"jawa barat kota bandung sukasari sarijadi"}]`},
+		{target: "/v1/search/province?q=Jawa+Barat&fields=id,city&format=csv", want: "id,city\n32.73.01.1001,Kota Bandung\n"},
+		{target: "/v1/regions/32.73?fields=city,population&format=xml", want: "<region><city>Kota Bandung</city></region>"},
+		{target: "/v1/search/province?q=Jawa+Barat&fields=id,population&format=csv", want: "id,population\n32.73.01.1001,\n"},
+		{target: "/v1/search/province?q=Jawa+Barat&fields=id,population", want: `[{"id":"32.73.01.1001"}]`},
+		{target: "/v1/regions/32.73?fields=name", status: fiber.StatusBadRequest, want: `unknown field \"name\"`},
+		{target: "/v1/search/province", status: fiber.StatusBadRequest, want: `"field":"q"`},
+		{target: "/v1/search/province?q=+++", status: fiber.StatusBadRequest, want: `"field":"q"`},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest("GET", tt.target, nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		status := tt.status
+		if status == 0 {
+			status = fiber.StatusOK
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != status || !strings.Contains(string(body), tt.want) {
+			t.Errorf("%s: got %d %s, want %d containing %s", tt.target, resp.StatusCode, body, status, tt.want)
+		}
+		if tt.target == "/v2/search/province?q=Jawa+Barat" && strings.Contains(string(body), "full_text") {
+			t.Errorf("expected /v2 to leave out full_text by default, got %s", body)
+		}
+	}
+}
+
+func TestProblemFields(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return Problem(c, service.NewFieldError("postal_code", "postal code parameter is required"))
+	})
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != fiber.StatusBadRequest || !strings.Contains(string(body), `"field":"postalCode"`) {
+		t.Errorf("got %d %s, want 400 naming the postalCode parameter", resp.StatusCode, body)
+	}
+}
+
+// openExportDB returns an in-memory database whose regions table holds rows.
+func openExportDB(t *testing.T, rows string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	query := `CREATE TABLE regions (id VARCHAR, subdistrict VARCHAR, district VARCHAR, city VARCHAR,
+		province VARCHAR, postal_code VARCHAR, full_text VARCHAR);`
+	if rows != "" {
+		query += "INSERT INTO regions VALUES " + rows
+	}
+	if _, err := db.Exec(query); err != nil {
+		t.Fatalf("failed to create regions: %v", err)
+	}
+	return db
+}
+
+func TestExportHandler(t *testing.T) {
+	db := openExportDB(t, `
+		('32.73.01.1001', 'Sarijadi', 'Sukasari', 'Kota Bandung', 'Jawa Barat', '40151', 'sarijadi'),
+		('32.73.01.1002', 'Sukarasa', 'Sukasari', 'Kota Bandung', 'Jawa Barat', NULL, 'sukarasa');
+	`)
+	h := New(service.New(db))
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/v1/export", h.httpCache(CacheExport), h.ExportHandler())
+
+	empty := New(service.New(openExportDB(t, "")))
+	app.Get("/empty/export", empty.ExportHandler())
+
+	get := func(target string, header map[string]string) (*http.Response, string) {
+		t.Helper()
+		req := httptest.NewRequest("GET", target, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	tests := []struct {
+		target      string
+		contentType string
+		body        string
+	}{
+		{target: "/v1/export", contentType: fiber.MIMEApplicationJSON,
+			body: `[{"id":"32.73.01.1001","subdistrict":"Sarijadi","district":"Sukasari","city":"Kota Bandung","province":"Jawa Barat","postal_code":"40151","full_text":"sarijadi"},` +
+				`{"id":"32.73.01.1002","subdistrict":"Sukarasa","district":"Sukasari","city":"Kota Bandung","province":"Jawa Barat","postal_code":"","full_text":"sukarasa"}]`},
+		{target: "/v1/export?format=csv&level=district", contentType: "text/csv",
+			body: "id,subdistrict,district,city,province,postal_code,full_text,latitude,longitude,area_km2,population\n" +
+				"32.73.01,,Sukasari,Kota Bandung,Jawa Barat,,jawa barat kota bandung sukasari,,,,\n"},
+		{target: "/v1/export?format=ndjson&level=district", contentType: "application/x-ndjson",
+			body: `{"id":"32.73.01","subdistrict":"","district":"Sukasari","city":"Kota Bandung","province":"Jawa Barat","postal_code":"","full_text":"jawa barat kota bandung sukasari"}` + "\n"},
+		{target: "/empty/export", contentType: fiber.MIMEApplicationJSON, body: "[]"},
+		{target: "/empty/export?format=csv", contentType: "text/csv", body: "id,subdistrict,district,city,province,postal_code,full_text,latitude,longitude,area_km2,population\n"},
+		{target: "/empty/export?format=ndjson", contentType: "application/x-ndjson", body: ""},
+	}
+	for _, tt := range tests {
+		resp, body := get(tt.target, nil)
+		if resp.StatusCode != fiber.StatusOK || !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), tt.contentType) || body != tt.body {
+			t.Errorf("%s: got %d %s\n%s\nwant %s\n%s", tt.target, resp.StatusCode, resp.Header.Get(fiber.HeaderContentType), body, tt.contentType, tt.body)
+		}
+	}
+
+	for _, target := range []string{"/v1/export?level=village", "/v1/export?format=xml"} {
+		if resp, body := get(target, nil); resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d %s", target, resp.StatusCode, body)
+		}
+	}
+
+	// Clients accepting gzip get a compressed body
+	resp, body := get("/v1/export?format=ndjson", map[string]string{fiber.HeaderAcceptEncoding: "br;q=1.0, gzip;q=0.5"})
+	if resp.Header.Get(fiber.HeaderContentEncoding) != "gzip" {
+		t.Fatalf("expected a gzip response, got %v", resp.Header)
+	}
+	zr, err := gzip.NewReader(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("body is not gzip: %v", err)
+	}
+	plain, _ := io.ReadAll(zr)
+	if lines := strings.Count(string(plain), "\n"); lines != 2 {
+		t.Errorf("expected 2 NDJSON lines, got %d: %s", lines, plain)
+	}
+
+	// A matching ETag answers 304 without a body
+	etag := resp.Header.Get(fiber.HeaderETag)
+	resp, body = get("/v1/export?format=ndjson", map[string]string{fiber.HeaderIfNoneMatch: etag})
+	if etag == "" || resp.StatusCode != fiber.StatusNotModified || body != "" {
+		t.Errorf("expected 304 for the ETag %q, got %d %q", etag, resp.StatusCode, body)
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":                    false,
+		"gzip":                true,
+		"GZIP, deflate":       true,
+		"deflate, gzip;q=0.5": true,
+		"gzip;q=0":            false,
+		"gzip; q=0.000":       false,
+		"br, gzip ; q=0.0":    false,
+		"x-gzip":              false,
+		"identity, *;q=0.1":   false,
+	}
+	for header, want := range tests {
+		if got := acceptsGzip(header); got != want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+// IslandSearchHandler handles the island search endpoint
+func (h *Handler) IslandSearchHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		// Extract and validate the q query parameter
+		query := c.Query("q")
+		if query == "" {
+			slog.WarnContext(c.UserContext(), "Island search query parameter missing", "ip", c.IP())
+			return Problem(c, service.NewFieldError("q", "Query parameter 'q' is required"))
+		}
+		opts, err := searchOptions(c)
+		if err != nil {
+			return Problem(c, err)
+		}
+
+		// Use the service to perform the search
+		page, err := h.svc.SearchIslandsPage(c.UserContext(), query, opts)
+		if err != nil {
+			return Problem(c, err)
+		}
+		return respondPage(h, c, "island", query, page, start)
+	}
+}
+
+// CityIslandsHandler handles the endpoint listing the islands of a city
+func (h *Handler) CityIslandsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Extract and validate the code from path parameter
+		code := c.Params("code")
+		if code == "" {
+			slog.WarnContext(c.UserContext(), "City code parameter missing", "ip", c.IP())
+			return Problem(c, service.NewFieldError("code", "City code parameter is required"))
+		}
+
+		// Use the service to list the islands
+		results, err := h.svc.IslandsByCity(c.UserContext(), code)
+		if err != nil {
+			return Problem(c, err)
+		}
+
+		// Return the islands in the negotiated format
+		return respond(c, "island", results)
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+// routeParam matches a Fiber path parameter such as :code.
+var routeParam = regexp.MustCompile(`:(\w+)`)
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(OpenAPISpec(), &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Fatalf("openapi version = %q, want 3.x", spec.OpenAPI)
+	}
+
+	app := fiber.New()
+	RegisterRoutes(app, New(service.New(nil)))
+
+	registered := make(map[string]bool)
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		path := routeParam.ReplaceAllString(route.Path, "{$1}")
+		method := strings.ToLower(route.Method)
+		registered[method+" "+path] = true
+		if _, ok := spec.Paths[path][method]; !ok {
+			t.Errorf("route %s %s has no entry in openapi.json", route.Method, path)
+		}
+	}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if !registered[method+" "+path] {
+				t.Errorf("openapi.json documents %s %s, which is not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
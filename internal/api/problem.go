@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+// MIMEApplicationProblemJSON is the content type of RFC 7807 error responses.
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Error codes used for failures that do not come from the service layer.
+const (
+	ErrCodeRouteNotFound = "ROUTE_NOT_FOUND"
+	ErrCodeUnauthorized  = "UNAUTHORIZED"
+	ErrCodeForbidden     = "FORBIDDEN"
+	ErrCodeRateLimited   = "RATE_LIMITED"
+	ErrCodeHTTP          = "HTTP_ERROR"
+	ErrCodeInternal      = "INTERNAL_ERROR"
+)
+
+// ProblemDetails is an RFC 7807 error response body, extended with the
+// service error code, the request ID and field-level details.
+type ProblemDetails struct {
+	Type      string               `json:"type"`
+	Title     string               `json:"title"`
+	Status    int                  `json:"status"`
+	Detail    string               `json:"detail,omitempty"`
+	Instance  string               `json:"instance,omitempty"`
+	Code      string               `json:"code"`
+	RequestID string               `json:"request_id,omitempty"`
+	Errors    []service.FieldError `json:"errors,omitempty"`
+}
+
+// errorStatuses maps service error codes to HTTP status codes.
+var errorStatuses = map[string]int{
+	service.ErrCodeInvalidInput:    fiber.StatusBadRequest,
+	service.ErrCodeNotFound:        fiber.StatusNotFound,
+	service.ErrCodeDatabaseFailure: fiber.StatusInternalServerError,
+}
+
+// problemFields maps the input names of service field errors to the request
+// parameters they are read from, where the two differ.
+var problemFields = map[string]string{
+	"query":       "q",
+	"postal_code": "postalCode",
+}
+
+// Problem writes err as an application/problem+json response. Service errors
+// are mapped by code and only their message is sent; the wrapped cause is
+// logged, and their field details name the request parameters. Any other
+// error is reported as an internal error without details.
+func Problem(c *fiber.Ctx, err error) error {
+	p := ProblemDetails{
+		Type:      "about:blank",
+		Status:    fiber.StatusInternalServerError,
+		Instance:  c.Path(),
+		Code:      ErrCodeInternal,
+		RequestID: RequestID(c),
+	}
+
+	var svcErr *service.Error
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &svcErr):
+		if status, ok := errorStatuses[svcErr.Code]; ok {
+			p.Status = status
+		}
+		p.Code = svcErr.Code
+		p.Detail = svcErr.Message
+		for _, detail := range svcErr.Details {
+			if param, ok := problemFields[detail.Field]; ok {
+				detail.Field = param
+			}
+			p.Errors = append(p.Errors, detail)
+		}
+	case errors.As(err, &fiberErr):
+		p.Status = fiberErr.Code
+		p.Code = ErrCodeHTTP
+		switch fiberErr.Code {
+		case fiber.StatusNotFound:
+			p.Code = ErrCodeRouteNotFound
+		case fiber.StatusUnauthorized:
+			p.Code = ErrCodeUnauthorized
+		case fiber.StatusForbidden:
+			p.Code = ErrCodeForbidden
+		case fiber.StatusTooManyRequests:
+			p.Code = ErrCodeRateLimited
+		}
+		p.Detail = fiberErr.Message
+	default:
+		p.Detail = "An unexpected error occurred"
+	}
+	p.Title = http.StatusText(p.Status)
+
+	if p.Status >= fiber.StatusInternalServerError {
+		slog.ErrorContext(c.UserContext(), "Request failed", "error", err, "code", p.Code, "path", c.Path())
+	}
+
+	return c.Status(p.Status).JSON(p, MIMEApplicationProblemJSON)
+}
+
+// ErrorHandler is a fiber.ErrorHandler that reports errors not handled by a
+// route, such as unknown paths, as problem+json.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	return Problem(c, err)
+}
+
+// RequestID returns the ID assigned to the request by the requestid middleware.
+func RequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	return id
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+// RegionHandler handles the region lookup endpoint.
+func (h *Handler) RegionHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Extract and validate the code from path parameter
+		code := c.Params("code")
+		if code == "" {
+			slog.WarnContext(c.UserContext(), "Region code parameter missing", "ip", c.IP())
+			return Problem(c, service.NewFieldError("code", "Region code parameter is required"))
+		}
+
+		// Use the service to look up the region and its requested attributes
+		fields := regionFields(c)
+		region, err := h.svc.GetByCodeFields(c.UserContext(), code, fields)
+		if err == nil {
+			regions := []service.Region{*region}
+			err = h.svc.WithAttributes(c.UserContext(), regions, parseInclude(c.Query("include")))
+			region = &regions[0]
+		}
+		if err != nil {
+			return Problem(c, err)
+		}
+
+		// Return the region, with only the selected fields, in the negotiated format
+		if fields != nil {
+			return respond(c, "region", projectRegion(*region, fields))
+		}
+		return respond(c, "region", region)
+	}
+}
+
+// AttributeSetsHandler lists the attribute sets that can be requested with ?include=.
+func (h *Handler) AttributeSetsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sets, err := h.svc.AttributeSets(c.UserContext())
+		if err != nil {
+			return Problem(c, err)
+		}
+		return respond(c, "attribute_set", sets)
+	}
+}
+
+// parseInclude splits a comma-separated include parameter into attribute set names.
+func parseInclude(include string) []string {
+	var names []string
+	for _, name := range strings.Split(include, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RegionStatsHandler handles the province and city statistics endpoint.
+func (h *Handler) RegionStatsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Extract and validate the code from path parameter
+		code := c.Params("code")
+		if code == "" {
+			slog.WarnContext(c.UserContext(), "Region code parameter missing", "ip", c.IP())
+			return Problem(c, service.NewFieldError("code", "Region code parameter is required"))
+		}
+
+		// Use the service to look up the stats, with the boundary on request
+		stats, err := h.svc.Stats(c.UserContext(), code, c.QueryBool("boundary"))
+		if err != nil {
+			return Problem(c, err)
+		}
+
+		// Return the stats in the negotiated format
+		return respond(c, "stats", stats)
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/auth"
+	"github.com/ilmimris/wilayah-indonesia/internal/metrics"
+	"github.com/ilmimris/wilayah-indonesia/internal/ratelimit"
+)
+
+// RegisterRoutes registers every API route on app. Each route must have an
+// entry in the OpenAPI specification served at /openapi.json.
+func RegisterRoutes(app *fiber.App, h *Handler) {
+	// Searches, lookups and GraphQL need the public scope and the export its own scope
+	public := h.authorize(auth.ScopePublic)
+	exportAuth := h.authorize(auth.ScopeExport)
+
+	// Searches and lookups, the export and GraphQL batches are rate limited separately
+	searchLimit := h.rateLimit(ratelimit.GroupSearch)
+	exportLimit := h.rateLimit(ratelimit.GroupExport)
+	batchLimit := h.rateLimit(ratelimit.GroupBatch)
+
+	// Searches, lookups and the export only change with the dataset, so they
+	// carry ETags and a Cache-Control max-age per group
+	searchCache := h.httpCache(CacheSearch)
+	lookupCache := h.httpCache(CacheLookup)
+	exportCache := h.httpCache(CacheExport)
+
+	// Define the search endpoint
+	app.Get("/v1/search", public, searchLimit, searchCache, h.SearchHandler())
+
+	// Define the district search endpoint
+	app.Get("/v1/search/district", public, searchLimit, searchCache, h.DistrictSearchHandler())
+
+	// Define the subdistrict search endpoint
+	app.Get("/v1/search/subdistrict", public, searchLimit, searchCache, h.SubdistrictSearchHandler())
+
+	// Define the city search endpoint
+	app.Get("/v1/search/city", public, searchLimit, searchCache, h.CitySearchHandler())
+
+	// Define the province search endpoint
+	app.Get("/v1/search/province", public, searchLimit, searchCache, h.ProvinceSearchHandler())
+
+	// Define the postal code search endpoint
+	app.Get("/v1/search/postal/:postalCode", public, searchLimit, searchCache, h.PostalCodeSearchHandler())
+
+	// Define the /v2 search endpoints, which wrap results in a data/meta envelope
+	v2 := app.Group("/v2", UseEnvelope())
+	v2.Get("/search", public, searchLimit, searchCache, h.SearchHandler())
+	v2.Get("/search/district", public, searchLimit, searchCache, h.DistrictSearchHandler())
+	v2.Get("/search/subdistrict", public, searchLimit, searchCache, h.SubdistrictSearchHandler())
+	v2.Get("/search/city", public, searchLimit, searchCache, h.CitySearchHandler())
+	v2.Get("/search/province", public, searchLimit, searchCache, h.ProvinceSearchHandler())
+	v2.Get("/search/postal/:postalCode", public, searchLimit, searchCache, h.PostalCodeSearchHandler())
+	v2.Get("/islands", public, searchLimit, searchCache, h.IslandSearchHandler())
+
+	// Define the region lookup endpoint
+	app.Get("/v1/regions/:code", public, searchLimit, lookupCache, h.RegionHandler())
+
+	// Define the province and city statistics endpoint
+	app.Get("/v1/regions/:code/stats", public, searchLimit, lookupCache, h.RegionStatsHandler())
+
+	// Define the island search endpoint
+	app.Get("/v1/islands", public, searchLimit, searchCache, h.IslandSearchHandler())
+
+	// Define the city islands listing endpoint
+	app.Get("/v1/cities/:code/islands", public, searchLimit, lookupCache, h.CityIslandsHandler())
+
+	// Define the attribute set listing endpoint
+	app.Get("/v1/attributes", public, searchLimit, lookupCache, h.AttributeSetsHandler())
+
+	// Define the full-dataset export endpoint
+	app.Get("/v1/export", exportAuth, exportLimit, exportCache, h.ExportHandler())
+
+	// Define the GraphQL endpoint over the region hierarchy
+	graphqlHandler := h.GraphQLHandler()
+	app.Get("/graphql", public, batchLimit, graphqlHandler)
+	app.Post("/graphql", public, batchLimit, graphqlHandler)
+
+	// Add the health check and the liveness and readiness probes, which are
+	// never authenticated so that probes work
+	app.Get("/healthz", h.HealthHandler())
+	app.Get("/livez", h.LivezHandler())
+	app.Get("/readyz", h.ReadyzHandler())
+
+	// Expose Prometheus metrics, to admin keys only when configured
+	app.Get("/metrics", h.metricsAuth(), metrics.Handler())
+
+	// Serve the OpenAPI specification and the documentation UI
+	app.Get("/openapi.json", OpenAPIHandler())
+	app.Get("/docs", DocsHandler())
+	app.Use("/docs", DocsAssetsHandler())
+}
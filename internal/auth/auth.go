@@ -0,0 +1,248 @@
+// Package auth authenticates API clients with API keys. Keys are configured
+// by their SHA-256 hash, so the secrets themselves are never stored, and each
+// has a label that identifies the client in logs and metrics. Every key is
+// granted scopes, which open route groups to it.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/logging"
+)
+
+// Scopes granted to API keys. ScopeAdmin grants every other scope as well.
+const (
+	ScopePublic = "public"
+	ScopeExport = "export"
+	ScopeAdmin  = "admin"
+)
+
+// HeaderAPIKey is the header carrying the API key, as an alternative to an
+// Authorization bearer token.
+const HeaderAPIKey = "X-API-Key"
+
+// LabelAttr is the log attribute holding the label of the authenticated key.
+const LabelAttr = "api_key"
+
+// Results reported to Observer.ObserveAuth.
+const (
+	ResultAllowed      = "allowed"
+	ResultUnauthorized = "unauthorized"
+	ResultForbidden    = "forbidden"
+)
+
+// AnonymousLabel is the label reported for requests without a known key.
+const AnonymousLabel = "anonymous"
+
+// Errors returned by Keyring.Authenticate.
+var (
+	ErrUnauthorized = errors.New("a valid API key is required")
+	ErrForbidden    = errors.New("the API key is not granted the scope")
+)
+
+// knownScopes are the scopes accepted in key definitions.
+var knownScopes = map[string]bool{
+	ScopePublic: true,
+	ScopeExport: true,
+	ScopeAdmin:  true,
+}
+
+// Key is an API key definition.
+type Key struct {
+	// Label names the client of the key in logs and metrics. It is not secret.
+	Label string `json:"label"`
+
+	// SHA256 is the hex-encoded SHA-256 hash of the key.
+	SHA256 string `json:"sha256"`
+
+	// Scopes lists the scopes granted to the key.
+	Scopes []string `json:"scopes"`
+}
+
+// allows reports whether the key is granted scope.
+func (k Key) allows(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Observer receives the outcome of every authentication, so that it can be
+// exported as metrics. Its methods are called concurrently.
+type Observer interface {
+	// ObserveAuth records the outcome of a request for scope by the key
+	// labelled label, which is AnonymousLabel for unknown keys.
+	ObserveAuth(label, scope, result string)
+}
+
+// nopObserver is the Observer used when none is configured.
+type nopObserver struct{}
+
+func (nopObserver) ObserveAuth(string, string, string) {}
+
+// Keyring holds the configured API keys, indexed by hash.
+type Keyring struct {
+	keys     map[string]Key
+	observer Observer
+}
+
+// Option configures optional behaviour of a Keyring.
+type Option func(*Keyring)
+
+// WithObserver reports the outcome of every authentication to o.
+func WithObserver(o Observer) Option {
+	return func(k *Keyring) {
+		k.observer = o
+	}
+}
+
+// NewKeyring returns a Keyring holding keys. It fails if a key has no label,
+// an invalid hash or an unknown scope, or if labels or hashes are repeated.
+func NewKeyring(keys []Key, opts ...Option) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]Key, len(keys)), observer: nopObserver{}}
+	for _, opt := range opts {
+		opt(k)
+	}
+	labels := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.Label == "" {
+			return nil, fmt.Errorf("API key without a label")
+		}
+		if labels[key.Label] {
+			return nil, fmt.Errorf("API key label %q is used twice", key.Label)
+		}
+		labels[key.Label] = true
+
+		hash := strings.ToLower(key.SHA256)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("API key %q must have a hex-encoded SHA-256 hash", key.Label)
+		}
+		if _, ok := k.keys[hash]; ok {
+			return nil, fmt.Errorf("API key %q has the same hash as another key", key.Label)
+		}
+		if len(key.Scopes) == 0 {
+			return nil, fmt.Errorf("API key %q has no scopes", key.Label)
+		}
+		for _, scope := range key.Scopes {
+			if !knownScopes[scope] {
+				return nil, fmt.Errorf("API key %q has unknown scope %q, must be one of public, export or admin", key.Label, scope)
+			}
+		}
+		k.keys[hash] = key
+	}
+	return k, nil
+}
+
+// Load reads the API keys defined in the JSON file at path and in env, either
+// of which may be empty. The file holds an array of Key objects. In env, keys
+// are separated by semicolons and written as label:sha256:scope[,scope...].
+func Load(path, env string, opts ...Option) (*Keyring, error) {
+	var keys []Key
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API keys file: %w", err)
+		}
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return nil, fmt.Errorf("failed to parse API keys file %s: %w", path, err)
+		}
+	}
+	for _, entry := range strings.Split(env, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid API key definition %q, expected label:sha256:scopes", parts[0])
+		}
+		keys = append(keys, Key{Label: parts[0], SHA256: parts[1], Scopes: strings.Split(parts[2], ",")})
+	}
+	return NewKeyring(keys, opts...)
+}
+
+// Len returns the number of keys in the keyring.
+func (k *Keyring) Len() int {
+	return len(k.keys)
+}
+
+// Hash returns the hex-encoded SHA-256 hash of an API key, as used in key
+// definitions.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKey returns the API key sent with a request in the X-API-Key header or
+// as an Authorization bearer token, or an empty string.
+func APIKey(c *fiber.Ctx) string {
+	if key := c.Get(HeaderAPIKey); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+type labelKey struct{}
+
+// Label returns the label of the key that authenticated the request of ctx,
+// or an empty string.
+func Label(ctx context.Context) string {
+	label, _ := ctx.Value(labelKey{}).(string)
+	return label
+}
+
+// Authenticate checks that apiKey is a known key granted scope and records
+// the outcome. It fails with ErrUnauthorized for unknown keys and with
+// ErrForbidden for keys lacking the scope. For known keys, the returned
+// context carries the label of the key and adds it to logs.
+func (k *Keyring) Authenticate(ctx context.Context, apiKey, scope string) (context.Context, error) {
+	key, ok := k.keys[Hash(apiKey)]
+	if apiKey == "" || !ok {
+		k.observer.ObserveAuth(AnonymousLabel, scope, ResultUnauthorized)
+		return ctx, ErrUnauthorized
+	}
+
+	ctx = context.WithValue(ctx, labelKey{}, key.Label)
+	ctx = logging.WithAttrs(ctx, slog.String(LabelAttr, key.Label))
+
+	if !key.allows(scope) {
+		k.observer.ObserveAuth(key.Label, scope, ResultForbidden)
+		return ctx, ErrForbidden
+	}
+	k.observer.ObserveAuth(key.Label, scope, ResultAllowed)
+	return ctx, nil
+}
+
+// Middleware lets through the requests sent with a key granted scope. Requests
+// without a known key fail with 401 Unauthorized and those whose key lacks the
+// scope with 403 Forbidden. The label of the key is stored in the user context
+// of the request and added to its logs.
+func (k *Keyring) Middleware(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, err := k.Authenticate(c.UserContext(), APIKey(c), scope)
+		c.SetUserContext(ctx)
+		switch {
+		case errors.Is(err, ErrUnauthorized):
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="wilayah-indonesia"`)
+			return fiber.NewError(fiber.StatusUnauthorized, "A valid API key is required")
+		case errors.Is(err, ErrForbidden):
+			return fiber.NewError(fiber.StatusForbidden, fmt.Sprintf("The API key is not granted the %s scope", scope))
+		}
+		return c.Next()
+	}
+}
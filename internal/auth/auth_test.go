@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// recordingObserver records the results reported to it.
+type recordingObserver struct {
+	results []string
+}
+
+func (o *recordingObserver) ObserveAuth(label, scope, result string) {
+	o.results = append(o.results, label+"/"+scope+"/"+result)
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	data := `[{"label": "partner", "sha256": "` + Hash("partner-secret") + `", "scopes": ["public", "export"]}]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write keys file: %v", err)
+	}
+
+	keyring, err := Load(path, "ops:"+Hash("ops-secret")+":admin")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if keyring.Len() != 2 {
+		t.Errorf("expected 2 keys, got %d", keyring.Len())
+	}
+
+	for _, env := range []string{
+		"ops:" + Hash("ops-secret"),
+		"ops:not-a-hash:admin",
+		"ops:" + Hash("ops-secret") + ":root",
+		"ops:" + Hash("a") + ":admin;ops:" + Hash("b") + ":admin",
+		"a:" + Hash("same") + ":admin;b:" + Hash("same") + ":admin",
+	} {
+		if _, err := Load("", env); err == nil {
+			t.Errorf("expected an error for %q", env)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	observer := &recordingObserver{}
+	keyring, err := NewKeyring([]Key{
+		{Label: "partner", SHA256: Hash("partner-secret"), Scopes: []string{ScopePublic}},
+		{Label: "ops", SHA256: Hash("ops-secret"), Scopes: []string{ScopeAdmin}},
+	}, WithObserver(observer))
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/search", keyring.Middleware(ScopePublic), func(c *fiber.Ctx) error {
+		return c.SendString(Label(c.UserContext()))
+	})
+	app.Get("/export", keyring.Middleware(ScopeExport), func(c *fiber.Ctx) error {
+		return c.SendString(Label(c.UserContext()))
+	})
+
+	tests := []struct {
+		path   string
+		header string
+		value  string
+		want   int
+	}{
+		{path: "/search", want: fiber.StatusUnauthorized},
+		{path: "/search", header: HeaderAPIKey, value: "wrong", want: fiber.StatusUnauthorized},
+		{path: "/search", header: HeaderAPIKey, value: "partner-secret", want: fiber.StatusOK},
+		{path: "/search", header: fiber.HeaderAuthorization, value: "Bearer partner-secret", want: fiber.StatusOK},
+		{path: "/export", header: HeaderAPIKey, value: "partner-secret", want: fiber.StatusForbidden},
+		{path: "/export", header: fiber.HeaderAuthorization, value: "Bearer ops-secret", want: fiber.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s with %s %q: got status %d, want %d", tt.path, tt.header, tt.value, resp.StatusCode, tt.want)
+		}
+		if tt.want == fiber.StatusUnauthorized && resp.Header.Get(fiber.HeaderWWWAuthenticate) == "" {
+			t.Errorf("%s with %q: expected a WWW-Authenticate header", tt.path, tt.value)
+		}
+	}
+
+	want := []string{
+		"anonymous/public/unauthorized",
+		"anonymous/public/unauthorized",
+		"partner/public/allowed",
+		"partner/public/allowed",
+		"partner/export/forbidden",
+		"ops/export/allowed",
+	}
+	if len(observer.results) != len(want) {
+		t.Fatalf("observed %v, want %v", observer.results, want)
+	}
+	for i := range want {
+		if observer.results[i] != want[i] {
+			t.Errorf("observation %d = %s, want %s", i, observer.results[i], want[i])
+		}
+	}
+}
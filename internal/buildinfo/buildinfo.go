@@ -0,0 +1,50 @@
+// Package buildinfo describes the running binary. Version, Commit and Date
+// are set at build time with -ldflags, for example:
+//
+//	go build -ldflags "-X github.com/ilmimris/wilayah-indonesia/internal/buildinfo.Version=v1.2.0" ./cmd/api
+//
+// Binaries built without them report the VCS revision recorded by the Go
+// toolchain, when there is one.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set with -ldflags -X at build time.
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the running binary.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date, GoVersion: runtime.Version()}
+	if info.Commit != "" {
+		return info
+	}
+	info.Commit = "unknown"
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = setting.Value
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			}
+		}
+	}
+	return info
+}
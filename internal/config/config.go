@@ -0,0 +1,539 @@
+// Package config loads the configuration of the API server. Every setting
+// has a default and can be set in a YAML or TOML file, in an environment
+// variable and with a command-line flag, which take precedence in that
+// order: flags over environment variables over the file over defaults.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/logging"
+	"github.com/ilmimris/wilayah-indonesia/internal/ratelimit"
+)
+
+// EnvFile is the environment variable naming the configuration file, as an
+// alternative to the -config flag.
+const EnvFile = "CONFIG_FILE"
+
+// Config is the configuration of the API server.
+type Config struct {
+	Server    Server    `yaml:"server" toml:"server"`
+	Database  Database  `yaml:"database" toml:"database"`
+	Search    Search    `yaml:"search" toml:"search"`
+	HTTPCache HTTPCache `yaml:"http_cache" toml:"http_cache"`
+	CORS      CORS      `yaml:"cors" toml:"cors"`
+	Auth      Auth      `yaml:"auth" toml:"auth"`
+	RateLimit RateLimit `yaml:"rate_limit" toml:"rate_limit"`
+	Logging   Logging   `yaml:"logging" toml:"logging"`
+	Tracing   Tracing   `yaml:"tracing" toml:"tracing"`
+}
+
+// Server configures the HTTP and gRPC listeners.
+type Server struct {
+	Addr               string        `yaml:"addr" toml:"addr"`
+	GRPCAddr           string        `yaml:"grpc_addr" toml:"grpc_addr"`
+	ProxyHeader        string        `yaml:"proxy_header" toml:"proxy_header"`
+	ReadTimeout        time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout       time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	ExportWriteTimeout time.Duration `yaml:"export_write_timeout" toml:"export_write_timeout"`
+	IdleTimeout        time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	BodyLimit          int           `yaml:"body_limit" toml:"body_limit"`
+	ShutdownDelay      time.Duration `yaml:"shutdown_delay" toml:"shutdown_delay"`
+	ShutdownTimeout    time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	ReadinessCacheTTL  time.Duration `yaml:"readiness_cache_ttl" toml:"readiness_cache_ttl"`
+	ReadinessTimeout   time.Duration `yaml:"readiness_timeout" toml:"readiness_timeout"`
+}
+
+// Database configures the DuckDB database.
+type Database struct {
+	Path string `yaml:"path" toml:"path"`
+}
+
+// Search configures result limits, fuzzy matching and caching of searches.
+type Search struct {
+	DefaultLimit int        `yaml:"default_limit" toml:"default_limit"`
+	MaxLimit     int        `yaml:"max_limit" toml:"max_limit"`
+	CacheSize    int        `yaml:"cache_size" toml:"cache_size"`
+	Similarity   Similarity `yaml:"similarity" toml:"similarity"`
+}
+
+// Similarity holds the minimum Jaro-Winkler similarity of fuzzy matches per
+// search kind.
+type Similarity struct {
+	District    float64 `yaml:"district" toml:"district"`
+	Subdistrict float64 `yaml:"subdistrict" toml:"subdistrict"`
+	City        float64 `yaml:"city" toml:"city"`
+	Province    float64 `yaml:"province" toml:"province"`
+	Islands     float64 `yaml:"islands" toml:"islands"`
+}
+
+// HTTPCache holds the Cache-Control max-age of each route group. Zero makes
+// clients revalidate every response with its ETag.
+type HTTPCache struct {
+	SearchMaxAge time.Duration `yaml:"search_max_age" toml:"search_max_age"`
+	LookupMaxAge time.Duration `yaml:"lookup_max_age" toml:"lookup_max_age"`
+	ExportMaxAge time.Duration `yaml:"export_max_age" toml:"export_max_age"`
+}
+
+// CORS configures cross-origin requests. CORS is disabled without origins.
+type CORS struct {
+	AllowOrigins []string      `yaml:"allow_origins" toml:"allow_origins"`
+	MaxAge       time.Duration `yaml:"max_age" toml:"max_age"`
+}
+
+// Auth configures API key authentication.
+type Auth struct {
+	KeysFile       string `yaml:"keys_file" toml:"keys_file"`
+	Keys           string `yaml:"keys" toml:"keys"`
+	ProtectMetrics bool   `yaml:"protect_metrics" toml:"protect_metrics"`
+}
+
+// RateLimit holds the rate limit of each route group, as accepted by
+// ratelimit.ParseLimit.
+type RateLimit struct {
+	Search string `yaml:"search" toml:"search"`
+	Export string `yaml:"export" toml:"export"`
+	Batch  string `yaml:"batch" toml:"batch"`
+}
+
+// Logging configures the logger.
+type Logging struct {
+	Format        string `yaml:"format" toml:"format"`
+	Level         string `yaml:"level" toml:"level"`
+	RedactQueries bool   `yaml:"redact_queries" toml:"redact_queries"`
+}
+
+// Tracing configures the trace exporter.
+type Tracing struct {
+	Exporter string `yaml:"exporter" toml:"exporter"`
+}
+
+// Default returns the configuration used for settings that are not set.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:               ":8080",
+			GRPCAddr:           ":9090",
+			ReadTimeout:        10 * time.Second,
+			WriteTimeout:       60 * time.Second,
+			ExportWriteTimeout: 30 * time.Minute,
+			IdleTimeout:        120 * time.Second,
+			BodyLimit:          1 << 20,
+			ShutdownDelay:      5 * time.Second,
+			ShutdownTimeout:    30 * time.Second,
+			ReadinessCacheTTL:  30 * time.Second,
+			ReadinessTimeout:   2 * time.Second,
+		},
+		Database: Database{Path: "data/regions.duckdb"},
+		Search: Search{
+			DefaultLimit: 10,
+			MaxLimit:     100,
+			CacheSize:    1000,
+			Similarity: Similarity{
+				District:    0.8,
+				Subdistrict: 0.8,
+				City:        0.8,
+				Province:    0.8,
+				Islands:     0.8,
+			},
+		},
+		HTTPCache: HTTPCache{
+			SearchMaxAge: 5 * time.Minute,
+			LookupMaxAge: time.Hour,
+			ExportMaxAge: time.Hour,
+		},
+		CORS:    CORS{MaxAge: time.Hour},
+		Logging: Logging{Format: logging.FormatText, Level: "info"},
+		Tracing: Tracing{Exporter: "none"},
+	}
+}
+
+// setting binds a configuration field to its environment variable and flag.
+type setting struct {
+	key    string
+	env    string
+	flag   string
+	usage  string
+	secret bool
+	field  func(*Config) any
+}
+
+// settings lists every setting, in the order they are printed.
+var settings = []setting{
+	{key: "server.addr", env: "LISTEN_ADDR", flag: "addr", usage: "HTTP listen address",
+		field: func(c *Config) any { return &c.Server.Addr }},
+	{key: "server.grpc_addr", env: "GRPC_LISTEN_ADDR", flag: "grpc-addr", usage: "gRPC listen address",
+		field: func(c *Config) any { return &c.Server.GRPCAddr }},
+	{key: "server.proxy_header", env: "PROXY_HEADER", flag: "proxy-header", usage: "header carrying the client IP behind a proxy",
+		field: func(c *Config) any { return &c.Server.ProxyHeader }},
+	{key: "server.read_timeout", env: "READ_TIMEOUT", flag: "read-timeout", usage: "maximum time to read a request",
+		field: func(c *Config) any { return &c.Server.ReadTimeout }},
+	{key: "server.write_timeout", env: "WRITE_TIMEOUT", flag: "write-timeout", usage: "maximum time to write a response",
+		field: func(c *Config) any { return &c.Server.WriteTimeout }},
+	{key: "server.export_write_timeout", env: "EXPORT_WRITE_TIMEOUT", flag: "export-write-timeout", usage: "maximum time to write an export, 0 for no limit",
+		field: func(c *Config) any { return &c.Server.ExportWriteTimeout }},
+	{key: "server.idle_timeout", env: "IDLE_TIMEOUT", flag: "idle-timeout", usage: "maximum time to keep an idle connection",
+		field: func(c *Config) any { return &c.Server.IdleTimeout }},
+	{key: "server.body_limit", env: "BODY_LIMIT", flag: "body-limit", usage: "maximum request body size in bytes",
+		field: func(c *Config) any { return &c.Server.BodyLimit }},
+	{key: "server.shutdown_delay", env: "SHUTDOWN_DELAY", flag: "shutdown-delay", usage: "time to report unready before draining",
+		field: func(c *Config) any { return &c.Server.ShutdownDelay }},
+	{key: "server.shutdown_timeout", env: "SHUTDOWN_TIMEOUT", flag: "shutdown-timeout", usage: "time allowed for requests to drain",
+		field: func(c *Config) any { return &c.Server.ShutdownTimeout }},
+	{key: "server.readiness_cache_ttl", env: "READINESS_CACHE_TTL", flag: "readiness-cache-ttl", usage: "time to reuse a successful readiness check, 0 to check on every probe",
+		field: func(c *Config) any { return &c.Server.ReadinessCacheTTL }},
+	{key: "server.readiness_timeout", env: "READINESS_TIMEOUT", flag: "readiness-timeout", usage: "time allowed for the readiness checks, 0 for no limit",
+		field: func(c *Config) any { return &c.Server.ReadinessTimeout }},
+	{key: "database.path", env: "DB_PATH", flag: "db-path", usage: "path to the DuckDB database file",
+		field: func(c *Config) any { return &c.Database.Path }},
+	{key: "search.default_limit", env: "SEARCH_DEFAULT_LIMIT", flag: "search-default-limit", usage: "results returned when a search sets no limit",
+		field: func(c *Config) any { return &c.Search.DefaultLimit }},
+	{key: "search.max_limit", env: "SEARCH_MAX_LIMIT", flag: "search-max-limit", usage: "largest limit a search may set",
+		field: func(c *Config) any { return &c.Search.MaxLimit }},
+	{key: "search.cache_size", env: "SEARCH_CACHE_SIZE", flag: "search-cache-size", usage: "search result pages kept in memory, 0 to disable",
+		field: func(c *Config) any { return &c.Search.CacheSize }},
+	{key: "search.similarity.district", env: "SIMILARITY_DISTRICT", flag: "similarity-district", usage: "minimum Jaro-Winkler similarity of district matches",
+		field: func(c *Config) any { return &c.Search.Similarity.District }},
+	{key: "search.similarity.subdistrict", env: "SIMILARITY_SUBDISTRICT", flag: "similarity-subdistrict", usage: "minimum Jaro-Winkler similarity of subdistrict matches",
+		field: func(c *Config) any { return &c.Search.Similarity.Subdistrict }},
+	{key: "search.similarity.city", env: "SIMILARITY_CITY", flag: "similarity-city", usage: "minimum Jaro-Winkler similarity of city matches",
+		field: func(c *Config) any { return &c.Search.Similarity.City }},
+	{key: "search.similarity.province", env: "SIMILARITY_PROVINCE", flag: "similarity-province", usage: "minimum Jaro-Winkler similarity of province matches",
+		field: func(c *Config) any { return &c.Search.Similarity.Province }},
+	{key: "search.similarity.islands", env: "SIMILARITY_ISLANDS", flag: "similarity-islands", usage: "minimum Jaro-Winkler similarity of island matches",
+		field: func(c *Config) any { return &c.Search.Similarity.Islands }},
+	{key: "http_cache.search_max_age", env: "CACHE_MAX_AGE_SEARCH", flag: "cache-max-age-search", usage: "Cache-Control max-age of search responses",
+		field: func(c *Config) any { return &c.HTTPCache.SearchMaxAge }},
+	{key: "http_cache.lookup_max_age", env: "CACHE_MAX_AGE_LOOKUP", flag: "cache-max-age-lookup", usage: "Cache-Control max-age of region lookup responses",
+		field: func(c *Config) any { return &c.HTTPCache.LookupMaxAge }},
+	{key: "http_cache.export_max_age", env: "CACHE_MAX_AGE_EXPORT", flag: "cache-max-age-export", usage: "Cache-Control max-age of export responses",
+		field: func(c *Config) any { return &c.HTTPCache.ExportMaxAge }},
+	{key: "cors.allow_origins", env: "CORS_ALLOW_ORIGINS", flag: "cors-allow-origins", usage: "comma-separated origins allowed to call the API, or *",
+		field: func(c *Config) any { return &c.CORS.AllowOrigins }},
+	{key: "cors.max_age", env: "CORS_MAX_AGE", flag: "cors-max-age", usage: "time browsers may cache preflight responses",
+		field: func(c *Config) any { return &c.CORS.MaxAge }},
+	{key: "auth.keys_file", env: "API_KEYS_FILE", flag: "api-keys-file", usage: "JSON file defining API keys",
+		field: func(c *Config) any { return &c.Auth.KeysFile }},
+	{key: "auth.keys", env: "API_KEYS", flag: "api-keys", usage: "API keys as label:sha256:scopes entries separated by semicolons", secret: true,
+		field: func(c *Config) any { return &c.Auth.Keys }},
+	{key: "auth.protect_metrics", env: "AUTH_PROTECT_METRICS", flag: "auth-protect-metrics", usage: "require an admin API key on /metrics",
+		field: func(c *Config) any { return &c.Auth.ProtectMetrics }},
+	{key: "rate_limit.search", env: "RATE_LIMIT_SEARCH", flag: "rate-limit-search", usage: "rate limit of the search group, such as 20/s",
+		field: func(c *Config) any { return &c.RateLimit.Search }},
+	{key: "rate_limit.export", env: "RATE_LIMIT_EXPORT", flag: "rate-limit-export", usage: "rate limit of the export group, such as 10/h",
+		field: func(c *Config) any { return &c.RateLimit.Export }},
+	{key: "rate_limit.batch", env: "RATE_LIMIT_BATCH", flag: "rate-limit-batch", usage: "rate limit of the GraphQL batch group, such as 60/m",
+		field: func(c *Config) any { return &c.RateLimit.Batch }},
+	{key: "logging.format", env: "LOG_FORMAT", flag: "log-format", usage: "log output format: text or json",
+		field: func(c *Config) any { return &c.Logging.Format }},
+	{key: "logging.level", env: "LOG_LEVEL", flag: "log-level", usage: "minimum log level: debug, info, warn or error",
+		field: func(c *Config) any { return &c.Logging.Level }},
+	{key: "logging.redact_queries", env: "LOG_REDACT_QUERIES", flag: "log-redact-queries", usage: "redact search terms and query strings from logs",
+		field: func(c *Config) any { return &c.Logging.RedactQueries }},
+	{key: "tracing.exporter", env: "OTEL_TRACES_EXPORTER", flag: "traces-exporter", usage: "trace exporter: otlp, stdout or none",
+		field: func(c *Config) any { return &c.Tracing.Exporter }},
+}
+
+// legacyPortEnv maps the environment variables holding a bare port to the
+// address settings they set, for deployments predating LISTEN_ADDR and
+// GRPC_LISTEN_ADDR. The address variables take precedence.
+var legacyPortEnv = []struct {
+	env   string
+	field func(*Config) *string
+}{
+	{env: "PORT", field: func(c *Config) *string { return &c.Server.Addr }},
+	{env: "GRPC_PORT", field: func(c *Config) *string { return &c.Server.GRPCAddr }},
+}
+
+// Load returns the configuration set by the command-line arguments args and
+// the environment, read with getenv, on top of the file named by the -config
+// flag or CONFIG_FILE. With -print-config, it writes the effective
+// configuration to stdout as YAML, with secrets redacted, and returns
+// flag.ErrHelp. The configuration is validated.
+func Load(args []string, getenv func(string) string, stdout io.Writer) (Config, error) {
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	path := fs.String("config", getenv(EnvFile), "YAML or TOML configuration file")
+	printConfig := fs.Bool("print-config", false, "print the effective configuration and exit")
+	for _, s := range settings {
+		if _, ok := s.field(&Config{}).(*bool); ok {
+			fs.Bool(s.flag, false, s.usage+" (env "+s.env+")")
+		} else {
+			fs.String(s.flag, "", s.usage+" (env "+s.env+")")
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg := Default()
+	if *path != "" {
+		if err := cfg.readFile(*path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, legacy := range legacyPortEnv {
+		if port := getenv(legacy.env); port != "" {
+			*legacy.field(&cfg) = ":" + port
+		}
+	}
+	for _, s := range settings {
+		if value := getenv(s.env); value != "" {
+			if err := set(s.field(&cfg), value); err != nil {
+				return Config{}, fmt.Errorf("invalid %s: %w", s.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name {
+				if err := set(s.field(&cfg), f.Value.String()); err != nil && flagErr == nil {
+					flagErr = fmt.Errorf("invalid -%s: %w", s.flag, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return Config{}, flagErr
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	if *printConfig {
+		enc := yaml.NewEncoder(stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(cfg.Redacted()); err != nil {
+			return Config{}, err
+		}
+		return Config{}, flag.ErrHelp
+	}
+	return cfg, nil
+}
+
+// readFile decodes the YAML or TOML file at path, chosen by its extension,
+// into c. Unknown settings are rejected so that typos do not go unnoticed.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("failed to parse configuration file %s: unknown setting %q", path, undecoded[0].String())
+		}
+	default:
+		return fmt.Errorf("configuration file %s must have a .yaml, .yml or .toml extension", path)
+	}
+	return nil
+}
+
+// set parses value into the field pointed to by field.
+func set(field any, value string) error {
+	switch f := field.(type) {
+	case *string:
+		*f = value
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		*f = b
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		*f = n
+	case *float64:
+		x, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*f = x
+	case *time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration, such as 30s", value)
+		}
+		*f = d
+	case *[]string:
+		*f = nil
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*f = append(*f, item)
+			}
+		}
+	default:
+		panic(fmt.Sprintf("config: unsupported setting type %T", field))
+	}
+	return nil
+}
+
+// Validate reports every invalid setting of c.
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+	}
+
+	if c.Server.Addr == "" {
+		invalid("server.addr", "must not be empty")
+	}
+	if c.Server.GRPCAddr == "" {
+		invalid("server.grpc_addr", "must not be empty")
+	}
+	for key, d := range map[string]time.Duration{
+		"server.read_timeout":         c.Server.ReadTimeout,
+		"server.write_timeout":        c.Server.WriteTimeout,
+		"server.export_write_timeout": c.Server.ExportWriteTimeout,
+		"server.idle_timeout":         c.Server.IdleTimeout,
+		"server.shutdown_delay":       c.Server.ShutdownDelay,
+		"server.shutdown_timeout":     c.Server.ShutdownTimeout,
+		"server.readiness_cache_ttl":  c.Server.ReadinessCacheTTL,
+		"server.readiness_timeout":    c.Server.ReadinessTimeout,
+		"http_cache.search_max_age":   c.HTTPCache.SearchMaxAge,
+		"http_cache.lookup_max_age":   c.HTTPCache.LookupMaxAge,
+		"http_cache.export_max_age":   c.HTTPCache.ExportMaxAge,
+		"cors.max_age":                c.CORS.MaxAge,
+	} {
+		if d < 0 {
+			invalid(key, "must not be negative")
+		}
+	}
+	if c.Server.BodyLimit <= 0 {
+		invalid("server.body_limit", "must be positive")
+	}
+	if c.Database.Path == "" {
+		invalid("database.path", "must not be empty")
+	}
+
+	if c.Search.MaxLimit < 1 {
+		invalid("search.max_limit", "must be positive")
+	}
+	if c.Search.DefaultLimit < 1 || c.Search.DefaultLimit > c.Search.MaxLimit {
+		invalid("search.default_limit", "must be between 1 and search.max_limit")
+	}
+	if c.Search.CacheSize < 0 {
+		invalid("search.cache_size", "must not be negative")
+	}
+	for key, threshold := range map[string]float64{
+		"search.similarity.district":    c.Search.Similarity.District,
+		"search.similarity.subdistrict": c.Search.Similarity.Subdistrict,
+		"search.similarity.city":        c.Search.Similarity.City,
+		"search.similarity.province":    c.Search.Similarity.Province,
+		"search.similarity.islands":     c.Search.Similarity.Islands,
+	} {
+		if threshold <= 0 || threshold > 1 {
+			invalid(key, "must be greater than 0 and at most 1")
+		}
+	}
+
+	for _, origin := range c.CORS.AllowOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			invalid("cors.allow_origins", "%q must be * or an origin such as https://example.com", origin)
+		}
+	}
+
+	for key, limit := range map[string]string{
+		"rate_limit.search": c.RateLimit.Search,
+		"rate_limit.export": c.RateLimit.Export,
+		"rate_limit.batch":  c.RateLimit.Batch,
+	} {
+		if _, err := ratelimit.ParseLimit(limit); err != nil {
+			invalid(key, "%v", err)
+		}
+	}
+
+	if c.Logging.Format != logging.FormatText && c.Logging.Format != logging.FormatJSON {
+		invalid("logging.format", "must be text or json")
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Logging.Level)); err != nil {
+		invalid("logging.level", "must be one of debug, info, warn or error")
+	}
+	switch c.Tracing.Exporter {
+	case "", "none", "otlp", "stdout", "console":
+	default:
+		invalid("tracing.exporter", "must be otlp, stdout or none")
+	}
+
+	// Maps are iterated in random order, so sort for stable messages
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return errors.Join(errs...)
+}
+
+// Redacted returns a copy of c whose secret settings are replaced by
+// logging.Redacted when set.
+func (c Config) Redacted() Config {
+	c.CORS.AllowOrigins = append([]string(nil), c.CORS.AllowOrigins...)
+	for _, s := range settings {
+		if field, ok := s.field(&c).(*string); s.secret && ok && *field != "" {
+			*field = logging.Redacted
+		}
+	}
+	return c
+}
+
+// LogValue logs every setting of c by key, with secrets redacted.
+func (c Config) LogValue() slog.Value {
+	c = c.Redacted()
+	attrs := make([]slog.Attr, 0, len(settings))
+	for _, s := range settings {
+		switch field := s.field(&c).(type) {
+		case *[]string:
+			attrs = append(attrs, slog.String(s.key, strings.Join(*field, ",")))
+		case *time.Duration:
+			attrs = append(attrs, slog.String(s.key, field.String()))
+		default:
+			attrs = append(attrs, slog.Any(s.key, deref(field)))
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// deref returns the value of a setting field.
+func deref(field any) any {
+	switch f := field.(type) {
+	case *string:
+		return *f
+	case *bool:
+		return *f
+	case *int:
+		return *f
+	case *float64:
+		return *f
+	}
+	return field
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env returns a getenv function reading from vars.
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: ":7000"
+  read_timeout: 5s
+database:
+  path: /data/file.duckdb
+search:
+  max_limit: 50
+  similarity:
+    city: 0.9
+cors:
+  allow_origins: ["https://example.com"]
+logging:
+  level: debug
+`)
+	cfg, err := Load([]string{"-config", path, "-log-level", "warn"}, env(map[string]string{
+		"DB_PATH":   "/data/env.duckdb",
+		"LOG_LEVEL": "error",
+		"GRPC_PORT": "9999",
+	}), nil)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	// Defaults, then the file, then the environment, then flags
+	if cfg.Server.WriteTimeout != 60*time.Second || cfg.Server.ExportWriteTimeout != 30*time.Minute {
+		t.Errorf("expected the default write timeouts, got %s and %s", cfg.Server.WriteTimeout, cfg.Server.ExportWriteTimeout)
+	}
+	if cfg.Server.Addr != ":7000" || cfg.Server.ReadTimeout != 5*time.Second || cfg.Search.MaxLimit != 50 || cfg.Search.Similarity.City != 0.9 {
+		t.Errorf("expected the file settings, got %+v", cfg)
+	}
+	if cfg.Database.Path != "/data/env.duckdb" || cfg.Server.GRPCAddr != ":9999" {
+		t.Errorf("expected the environment to override the file, got %+v", cfg)
+	}
+	if cfg.Logging.Level != "warn" {
+		t.Errorf("expected the flag to override the environment, got %q", cfg.Logging.Level)
+	}
+	if len(cfg.CORS.AllowOrigins) != 1 || cfg.CORS.AllowOrigins[0] != "https://example.com" {
+		t.Errorf("unexpected CORS origins %v", cfg.CORS.AllowOrigins)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[server]
+addr = ":7000"
+shutdown_delay = "1s"
+
+[auth]
+protect_metrics = true
+
+[rate_limit]
+search = "20/s"
+`)
+	cfg, err := Load(nil, env(map[string]string{EnvFile: path, "PORT": "8000", "LISTEN_ADDR": "127.0.0.1:8001"}), nil)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.Server.ShutdownDelay != time.Second || !cfg.Auth.ProtectMetrics || cfg.RateLimit.Search != "20/s" {
+		t.Errorf("expected the file settings, got %+v", cfg)
+	}
+	if cfg.Server.Addr != "127.0.0.1:8001" {
+		t.Errorf("expected LISTEN_ADDR to take precedence over PORT, got %q", cfg.Server.Addr)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		file string
+		want string
+	}{
+		{name: "unknown file setting", file: "server:\n  adr: \":80\"\n", want: "adr"},
+		{name: "invalid env value", env: map[string]string{"BODY_LIMIT": "big"}, want: "BODY_LIMIT"},
+		{name: "invalid flag value", args: []string{"-read-timeout", "soon"}, want: "-read-timeout"},
+		{name: "limits", env: map[string]string{"SEARCH_DEFAULT_LIMIT": "200"}, want: "search.default_limit"},
+		{name: "similarity", env: map[string]string{"SIMILARITY_CITY": "1.5"}, want: "search.similarity.city"},
+		{name: "origin", env: map[string]string{"CORS_ALLOW_ORIGINS": "example.com"}, want: "cors.allow_origins"},
+		{name: "rate limit", env: map[string]string{"RATE_LIMIT_BATCH": "fast"}, want: "rate_limit.batch"},
+		{name: "export timeout", env: map[string]string{"EXPORT_WRITE_TIMEOUT": "-1s"}, want: "server.export_write_timeout"},
+		{name: "readiness cache", env: map[string]string{"READINESS_CACHE_TTL": "-1s"}, want: "server.readiness_cache_ttl"},
+		{name: "readiness timeout", env: map[string]string{"READINESS_TIMEOUT": "-1s"}, want: "server.readiness_timeout"},
+		{name: "log format", env: map[string]string{"LOG_FORMAT": "xml"}, want: "logging.format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, "config.yml", tt.file)}, args...)
+			}
+			_, err := Load(args, env(tt.env), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPrintConfigRedactsSecrets(t *testing.T) {
+	var out bytes.Buffer
+	_, err := Load([]string{"-print-config"}, env(map[string]string{"API_KEYS": "ops:abc:admin"}), &out)
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("expected flag.ErrHelp, got %v", err)
+	}
+	if strings.Contains(out.String(), "ops:abc") || !strings.Contains(out.String(), "keys: '[REDACTED]'") {
+		t.Errorf("expected the API keys to be redacted, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "read_timeout: 10s") {
+		t.Errorf("expected durations to be printed as such, got:\n%s", out.String())
+	}
+}
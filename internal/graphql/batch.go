@@ -0,0 +1,145 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+// batch holds a set of sibling regions, such as every city returned for a
+// list of provinces. The first time a field needs the children, child counts
+// or parents of one region, they are loaded for all siblings with a single
+// service call, so each level of a query costs one query instead of one per
+// region.
+type batch struct {
+	svc     *service.Service
+	regions []service.Region
+
+	childrenOnce sync.Once
+	children     map[string][]*regionResolver
+	childrenErr  error
+
+	countsOnce sync.Once
+	counts     map[string]int
+	countsErr  error
+
+	parentsMu sync.Mutex
+	parents   map[int]*parentLoad
+}
+
+// parentLoad caches the parents of the regions of a batch at one code length.
+type parentLoad struct {
+	once    sync.Once
+	parents map[string]*regionResolver
+	err     error
+}
+
+// newBatch wraps regions in resolvers sharing a single batch.
+func newBatch(svc *service.Service, regions []service.Region) []*regionResolver {
+	b := &batch{svc: svc, regions: regions, parents: make(map[int]*parentLoad)}
+	resolvers := make([]*regionResolver, len(regions))
+	for i := range regions {
+		resolvers[i] = &regionResolver{region: regions[i], batch: b}
+	}
+	return resolvers
+}
+
+// childrenOf returns the children of code, loading the children of every
+// region in the batch on first use. The children form the next batch.
+func (b *batch) childrenOf(ctx context.Context, code string) ([]*regionResolver, error) {
+	b.childrenOnce.Do(func() {
+		codes := make([]string, len(b.regions))
+		for i, r := range b.regions {
+			codes[i] = r.ID
+		}
+		byParent, err := b.svc.ChildrenOf(ctx, codes)
+		if err != nil {
+			b.childrenErr = err
+			return
+		}
+
+		// Keep every child in one batch so the next level is batched too
+		var all []service.Region
+		for _, parent := range codes {
+			all = append(all, byParent[parent]...)
+		}
+		resolvers := newBatch(b.svc, all)
+		b.children = make(map[string][]*regionResolver, len(codes))
+		for _, parent := range codes {
+			n := len(byParent[parent])
+			b.children[parent], resolvers = resolvers[:n:n], resolvers[n:]
+		}
+	})
+	if b.childrenErr != nil {
+		return nil, b.childrenErr
+	}
+	children := b.children[code]
+	if children == nil {
+		children = []*regionResolver{}
+	}
+	return children, nil
+}
+
+// countOf returns the number of children of code, counting the children of
+// every region in the batch on first use.
+func (b *batch) countOf(ctx context.Context, code string) (int32, error) {
+	b.countsOnce.Do(func() {
+		codes := make([]string, len(b.regions))
+		for i, r := range b.regions {
+			codes[i] = r.ID
+		}
+		b.counts, b.countsErr = b.svc.CountChildren(ctx, codes)
+	})
+	if b.countsErr != nil {
+		return 0, b.countsErr
+	}
+	return int32(b.counts[code]), nil
+}
+
+// parentOf returns the ancestor of code at the given code length, loading
+// the ancestors of every region in the batch on first use.
+func (b *batch) parentOf(ctx context.Context, code string, length int) (*regionResolver, error) {
+	b.parentsMu.Lock()
+	load, ok := b.parents[length]
+	if !ok {
+		load = &parentLoad{}
+		b.parents[length] = load
+	}
+	b.parentsMu.Unlock()
+
+	load.once.Do(func() {
+		seen := make(map[string]bool)
+		var codes []string
+		for _, r := range b.regions {
+			if len(r.ID) > length && !seen[r.ID[:length]] {
+				seen[r.ID[:length]] = true
+				codes = append(codes, r.ID[:length])
+			}
+		}
+		byCode, err := b.svc.RegionsByCode(ctx, codes)
+		if err != nil {
+			load.err = err
+			return
+		}
+
+		// Order the parents by code so that their own batch is deterministic
+		regions := make([]service.Region, 0, len(byCode))
+		for _, c := range codes {
+			if r, ok := byCode[c]; ok {
+				regions = append(regions, r)
+			}
+		}
+		load.parents = make(map[string]*regionResolver, len(regions))
+		for _, resolver := range newBatch(b.svc, regions) {
+			load.parents[resolver.region.ID] = resolver
+		}
+	})
+	if load.err != nil {
+		return nil, load.err
+	}
+	if len(code) < length {
+		return nil, nil
+	}
+	return load.parents[code[:length]], nil
+}
@@ -0,0 +1,62 @@
+// Package graphql serves the region hierarchy over GraphQL. Provinces,
+// cities, districts and villages are resolved through service.Service, with
+// the relations of sibling regions loaded in one query per level.
+package graphql
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2"
+	graphqlgo "github.com/graph-gophers/graphql-go"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+//go:embed schema.graphql
+var schemaString string
+
+// maxDepth limits the nesting of queries, which is enough to walk from a
+// province down to its villages and back up.
+const maxDepth = 10
+
+// Request is the body of a GraphQL request.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewSchema parses the GraphQL schema with resolvers backed by svc.
+func NewSchema(svc *service.Service) (*graphqlgo.Schema, error) {
+	return graphqlgo.ParseSchema(schemaString, &queryResolver{svc: svc},
+		graphqlgo.UseStringDescriptions(),
+		graphqlgo.MaxDepth(maxDepth),
+	)
+}
+
+// Handler returns a fiber.Handler executing GraphQL requests against schema.
+// POST requests carry a JSON body; GET requests pass the query, operationName
+// and JSON-encoded variables as query parameters.
+func Handler(schema *graphqlgo.Schema) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req Request
+		if c.Method() == fiber.MethodGet {
+			req.Query = c.Query("query")
+			req.OperationName = c.Query("operationName")
+			if variables := c.Query("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+					return service.NewFieldError("variables", "variables must be a JSON object")
+				}
+			}
+		} else if err := json.Unmarshal(c.Body(), &req); err != nil {
+			return service.NewFieldError("body", "request body must be a JSON object with a query")
+		}
+		if req.Query == "" {
+			return service.NewFieldError("query", "GraphQL query is required")
+		}
+
+		response := schema.Exec(c.UserContext(), req.Query, req.OperationName, req.Variables)
+		return c.JSON(response)
+	}
+}
@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	_ "github.com/marcboeker/go-duckdb"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+// countingHandler counts the log records with a given message.
+type countingHandler struct {
+	message string
+	count   *atomic.Int32
+}
+
+func (h countingHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h countingHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h countingHandler) WithGroup(string) slog.Handler            { return h }
+func (h countingHandler) Handle(_ context.Context, r slog.Record) error {
+	if r.Message == h.message {
+		h.count.Add(1)
+	}
+	return nil
+}
+
+func TestGraphQL(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE regions (id VARCHAR, subdistrict VARCHAR, district VARCHAR, city VARCHAR,
+			province VARCHAR, postal_code VARCHAR, full_text VARCHAR);
+		INSERT INTO regions VALUES
+			('32.73.01.1001', 'Sarijadi', 'Sukasari', 'Kota Bandung', 'Jawa Barat', '40151', ''),
+			('32.73.01.1002', 'Sukarasa', 'Sukasari', 'Kota Bandung', 'Jawa Barat', NULL, ''),
+			('32.73.02.1001', 'Hegarmanah', 'Cidadap', 'Kota Bandung', 'Jawa Barat', '40141', ''),
+			('32.74.01.1001', 'Harjamukti', 'Harjamukti', 'Kota Cirebon', 'Jawa Barat', '45143', ''),
+			('31.71.01.1001', 'Gambir', 'Gambir', 'Kota Adm. Jakarta Pusat', 'DKI Jakarta', '10110', '');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create regions: %v", err)
+	}
+
+	// Count the children queries to check that each level is batched
+	var childQueries atomic.Int32
+	logger := slog.New(countingHandler{message: "Processing children request", count: &childQueries})
+
+	schema, err := NewSchema(service.New(db, service.WithLogger(logger)))
+	if err != nil {
+		t.Fatalf("NewSchema returned error: %v", err)
+	}
+	app := fiber.New()
+	app.Post("/graphql", Handler(schema))
+
+	body := `{"query": "{ provinces { code name cityCount cities { name districts { name villages { code postalCode } } } } }"}`
+	req := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+
+	var result struct {
+		Data struct {
+			Provinces []struct {
+				Code      string
+				Name      string
+				CityCount int
+				Cities    []struct {
+					Name      string
+					Districts []struct {
+						Name     string
+						Villages []struct {
+							Code       string
+							PostalCode *string
+						}
+					}
+				}
+			}
+		}
+		Errors []json.RawMessage
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("failed to decode response %s: %v", data, err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %s", data)
+	}
+
+	provinces := result.Data.Provinces
+	if len(provinces) != 2 || provinces[1].Code != "32" || provinces[1].Name != "Jawa Barat" || provinces[1].CityCount != 2 {
+		t.Fatalf("unexpected provinces: %s", data)
+	}
+	bandung := provinces[1].Cities[0]
+	if bandung.Name != "Kota Bandung" || len(bandung.Districts) != 2 || len(bandung.Districts[0].Villages) != 2 {
+		t.Errorf("unexpected cities: %s", data)
+	}
+	if village := bandung.Districts[0].Villages[1]; village.Code != "32.73.01.1002" || village.PostalCode != nil {
+		t.Errorf("unexpected village: %+v", village)
+	}
+
+	// One query for the provinces and one for each level below them
+	if n := childQueries.Load(); n != 4 {
+		t.Errorf("expected 4 children queries, got %d", n)
+	}
+
+	// Village lookups resolve their parents, and errors carry the service code
+	body = `{"query": "query($code: String!) { village(code: $code) { name district { name } city { name province { name } } } district(code: \"32\") { name } }", "variables": {"code": "32.73.02.1001"}}`
+	req = httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+	resp, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	data, _ = io.ReadAll(resp.Body)
+	for _, want := range []string{`"district":{"name":"Cidadap"}`, `"province":{"name":"Jawa Barat"}`, `"code":"INVALID_INPUT"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("response %s does not contain %s", data, want)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+// Code lengths of the parent levels resolved through a batch.
+const (
+	provinceCodeLength = 2
+	cityCodeLength     = 5
+	districtCodeLength = 8
+)
+
+// queryResolver resolves the fields of the Query type.
+type queryResolver struct {
+	svc *service.Service
+}
+
+// searchArgs holds the arguments of the search fields.
+type searchArgs struct {
+	Q      string
+	Limit  int32
+	Offset int32
+}
+
+// codeArgs holds the arguments of the lookup fields.
+type codeArgs struct {
+	Code string
+}
+
+// postalCodeArgs holds the arguments of the postalCode field.
+type postalCodeArgs struct {
+	Code   string
+	Limit  int32
+	Offset int32
+}
+
+// Provinces lists every province.
+func (q *queryResolver) Provinces(ctx context.Context) ([]*regionResolver, error) {
+	byParent, err := q.svc.ChildrenOf(ctx, []string{""})
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	return newBatch(q.svc, byParent[""]), nil
+}
+
+// Province looks up a province by code.
+func (q *queryResolver) Province(ctx context.Context, args codeArgs) (*regionResolver, error) {
+	return q.lookup(ctx, args.Code, service.LevelProvince)
+}
+
+// City looks up a city by code.
+func (q *queryResolver) City(ctx context.Context, args codeArgs) (*regionResolver, error) {
+	return q.lookup(ctx, args.Code, service.LevelCity)
+}
+
+// District looks up a district by code.
+func (q *queryResolver) District(ctx context.Context, args codeArgs) (*regionResolver, error) {
+	return q.lookup(ctx, args.Code, service.LevelDistrict)
+}
+
+// Village looks up a village by code.
+func (q *queryResolver) Village(ctx context.Context, args codeArgs) (*regionResolver, error) {
+	return q.lookup(ctx, args.Code, service.LevelSubdistrict)
+}
+
+// Search runs a full-text search across all region names.
+func (q *queryResolver) Search(ctx context.Context, args searchArgs) ([]*regionResolver, error) {
+	return q.search(ctx, service.SearchAll, args.Q, args.Limit, args.Offset)
+}
+
+// SearchDistricts searches villages by district name.
+func (q *queryResolver) SearchDistricts(ctx context.Context, args searchArgs) ([]*regionResolver, error) {
+	return q.search(ctx, service.SearchDistrict, args.Q, args.Limit, args.Offset)
+}
+
+// SearchVillages searches villages by name.
+func (q *queryResolver) SearchVillages(ctx context.Context, args searchArgs) ([]*regionResolver, error) {
+	return q.search(ctx, service.SearchSubdistrict, args.Q, args.Limit, args.Offset)
+}
+
+// SearchCities searches villages by city name.
+func (q *queryResolver) SearchCities(ctx context.Context, args searchArgs) ([]*regionResolver, error) {
+	return q.search(ctx, service.SearchCity, args.Q, args.Limit, args.Offset)
+}
+
+// SearchProvinces searches villages by province name.
+func (q *queryResolver) SearchProvinces(ctx context.Context, args searchArgs) ([]*regionResolver, error) {
+	return q.search(ctx, service.SearchProvince, args.Q, args.Limit, args.Offset)
+}
+
+// PostalCode lists the villages with a postal code. An unknown postal code
+// yields an empty list rather than an error.
+func (q *queryResolver) PostalCode(ctx context.Context, args postalCodeArgs) ([]*regionResolver, error) {
+	regions, err := q.search(ctx, service.SearchPostalCode, args.Code, args.Limit, args.Offset)
+	if service.IsError(err, service.ErrCodeNotFound) {
+		return []*regionResolver{}, nil
+	}
+	return regions, err
+}
+
+// lookup returns the region with code, or nil when the code is unknown. The
+// code must belong to level.
+func (q *queryResolver) lookup(ctx context.Context, code, level string) (*regionResolver, error) {
+	if service.LevelOfCode(code) != level {
+		return nil, resolverError(service.NewFieldError("code", "code is not a valid "+level+" code"))
+	}
+	regions, err := q.svc.RegionsByCode(ctx, []string{code})
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	region, ok := regions[code]
+	if !ok {
+		return nil, nil
+	}
+	return newBatch(q.svc, []service.Region{region})[0], nil
+}
+
+// search runs a paginated search of the given kind.
+func (q *queryResolver) search(ctx context.Context, kind, query string, limit, offset int32) ([]*regionResolver, error) {
+	page, err := q.svc.SearchPage(ctx, kind, query, service.SearchOptions{Limit: int(limit), Offset: int(offset)})
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	return newBatch(q.svc, page.Items), nil
+}
+
+// regionResolver resolves the Province, City, District and Village types,
+// which all wrap a service.Region. Relations are loaded through its batch.
+type regionResolver struct {
+	region service.Region
+	batch  *batch
+}
+
+// Code returns the Kemendagri code of the region.
+func (r *regionResolver) Code() string {
+	return r.region.ID
+}
+
+// Name returns the name of the region at its own level.
+func (r *regionResolver) Name() string {
+	switch service.LevelOfCode(r.region.ID) {
+	case service.LevelProvince:
+		return r.region.Province
+	case service.LevelCity:
+		return r.region.City
+	case service.LevelDistrict:
+		return r.region.District
+	default:
+		return r.region.Subdistrict
+	}
+}
+
+// PostalCode returns the postal code of a village, if known.
+func (r *regionResolver) PostalCode() *string {
+	if r.region.PostalCode == "" {
+		return nil
+	}
+	return &r.region.PostalCode
+}
+
+// Latitude returns the latitude of a province or city, if known.
+func (r *regionResolver) Latitude() *float64 {
+	return r.region.Latitude
+}
+
+// Longitude returns the longitude of a province or city, if known.
+func (r *regionResolver) Longitude() *float64 {
+	return r.region.Longitude
+}
+
+// AreaKm2 returns the area of a province or city, if known.
+func (r *regionResolver) AreaKm2() *float64 {
+	return r.region.AreaKm2
+}
+
+// Population returns the population of a province or city, if known.
+func (r *regionResolver) Population() *int32 {
+	if r.region.Population == nil {
+		return nil
+	}
+	population := int32(*r.region.Population)
+	return &population
+}
+
+// Province returns the province containing the region.
+func (r *regionResolver) Province(ctx context.Context) (*regionResolver, error) {
+	return r.parent(ctx, provinceCodeLength)
+}
+
+// City returns the city containing the region.
+func (r *regionResolver) City(ctx context.Context) (*regionResolver, error) {
+	return r.parent(ctx, cityCodeLength)
+}
+
+// District returns the district containing the village.
+func (r *regionResolver) District(ctx context.Context) (*regionResolver, error) {
+	return r.parent(ctx, districtCodeLength)
+}
+
+// Cities lists the cities of a province.
+func (r *regionResolver) Cities(ctx context.Context) ([]*regionResolver, error) {
+	return r.children(ctx)
+}
+
+// Districts lists the districts of a city.
+func (r *regionResolver) Districts(ctx context.Context) ([]*regionResolver, error) {
+	return r.children(ctx)
+}
+
+// Villages lists the villages of a district.
+func (r *regionResolver) Villages(ctx context.Context) ([]*regionResolver, error) {
+	return r.children(ctx)
+}
+
+// CityCount returns the number of cities in a province.
+func (r *regionResolver) CityCount(ctx context.Context) (int32, error) {
+	return r.count(ctx)
+}
+
+// DistrictCount returns the number of districts in a city.
+func (r *regionResolver) DistrictCount(ctx context.Context) (int32, error) {
+	return r.count(ctx)
+}
+
+// VillageCount returns the number of villages in a district.
+func (r *regionResolver) VillageCount(ctx context.Context) (int32, error) {
+	return r.count(ctx)
+}
+
+func (r *regionResolver) parent(ctx context.Context, length int) (*regionResolver, error) {
+	parent, err := r.batch.parentOf(ctx, r.region.ID, length)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	if parent == nil {
+		return nil, resolverError(service.NewError(service.ErrCodeNotFound, "parent region not found"))
+	}
+	return parent, nil
+}
+
+func (r *regionResolver) children(ctx context.Context) ([]*regionResolver, error) {
+	children, err := r.batch.childrenOf(ctx, r.region.ID)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	return children, nil
+}
+
+func (r *regionResolver) count(ctx context.Context) (int32, error) {
+	n, err := r.batch.countOf(ctx, r.region.ID)
+	if err != nil {
+		return 0, resolverError(err)
+	}
+	return n, nil
+}
+
+// gqlError is a resolver error that carries the service error code in its
+// extensions and hides the underlying cause from clients.
+type gqlError struct {
+	message string
+	code    string
+}
+
+func (e *gqlError) Error() string {
+	return e.message
+}
+
+// Extensions implements the extensions interface of graphql-go.
+func (e *gqlError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+// resolverError converts a service error into a gqlError. The cause of
+// database failures is logged rather than returned.
+func resolverError(err error) error {
+	var svcErr *service.Error
+	if !errors.As(err, &svcErr) {
+		slog.Error("GraphQL resolver failed", "error", err)
+		return &gqlError{message: "an unexpected error occurred", code: "INTERNAL_ERROR"}
+	}
+	if svcErr.Code == service.ErrCodeDatabaseFailure {
+		slog.Error("GraphQL resolver failed", "error", err)
+	}
+	return &gqlError{message: svcErr.Message, code: svcErr.Code}
+}
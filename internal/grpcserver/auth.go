@@ -0,0 +1,84 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/auth"
+	wilayahv1 "github.com/ilmimris/wilayah-indonesia/proto/wilayah/v1"
+)
+
+// regionMethodPrefix prefixes the full method names of the region service.
+// The health checking and reflection services are left open, like the
+// probes and API documentation of the HTTP server.
+var regionMethodPrefix = "/" + wilayahv1.RegionService_ServiceDesc.ServiceName + "/"
+
+// AuthOptions returns the server options requiring the calls to the region
+// service to carry an API key of keyring granted the public scope, in the
+// x-api-key metadata or as an authorization bearer token. Calls without a
+// known key fail with Unauthenticated, and those whose key lacks the scope
+// with PermissionDenied. The label of the key is stored in the context of the
+// call, as by the HTTP middleware.
+func AuthOptions(keyring *auth.Keyring) []grpc.ServerOption {
+	authenticate := func(ctx context.Context, method string) (context.Context, error) {
+		if !strings.HasPrefix(method, regionMethodPrefix) {
+			return ctx, nil
+		}
+		ctx, err := keyring.Authenticate(ctx, metadataAPIKey(ctx), auth.ScopePublic)
+		switch {
+		case errors.Is(err, auth.ErrUnauthorized):
+			return ctx, status.Error(codes.Unauthenticated, "a valid API key is required")
+		case errors.Is(err, auth.ErrForbidden):
+			return ctx, status.Errorf(codes.PermissionDenied, "the API key is not granted the %s scope", auth.ScopePublic)
+		}
+		return ctx, nil
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, err := authenticate(ctx, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := authenticate(ss.Context(), info.FullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		}),
+	}
+}
+
+// metadataAPIKey returns the API key sent with a call in the x-api-key
+// metadata or as an authorization bearer token, or an empty string.
+func metadataAPIKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get(auth.HeaderAPIKey); len(keys) > 0 && keys[0] != "" {
+		return keys[0]
+	}
+	for _, value := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+// contextStream is a grpc.ServerStream whose context is replaced.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
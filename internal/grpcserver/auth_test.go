@@ -0,0 +1,76 @@
+package grpcserver
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/auth"
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+	wilayahv1 "github.com/ilmimris/wilayah-indonesia/proto/wilayah/v1"
+)
+
+func TestAuthOptions(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`
+		CREATE TABLE regions (id VARCHAR, subdistrict VARCHAR, district VARCHAR, city VARCHAR,
+			province VARCHAR, postal_code VARCHAR, full_text VARCHAR);
+		INSERT INTO regions VALUES ('31.71.01.1001', 'Gambir', 'Gambir', 'Kota Adm. Jakarta Pusat', 'DKI Jakarta', '10110', '');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create regions: %v", err)
+	}
+
+	keyring, err := auth.NewKeyring([]auth.Key{
+		{Label: "partner", SHA256: auth.Hash("partner-key"), Scopes: []string{auth.ScopePublic}},
+		{Label: "exporter", SHA256: auth.Hash("export-key"), Scopes: []string{auth.ScopeExport}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create keyring: %v", err)
+	}
+	conn := serve(t, service.New(db), AuthOptions(keyring)...)
+	client := wilayahv1.NewRegionServiceClient(conn)
+
+	tests := []struct {
+		name string
+		md   metadata.MD
+		want codes.Code
+	}{
+		{name: "no key", want: codes.Unauthenticated},
+		{name: "unknown key", md: metadata.Pairs("x-api-key", "other"), want: codes.Unauthenticated},
+		{name: "missing scope", md: metadata.Pairs("x-api-key", "export-key"), want: codes.PermissionDenied},
+		{name: "api key", md: metadata.Pairs("x-api-key", "partner-key"), want: codes.OK},
+		{name: "bearer token", md: metadata.Pairs("authorization", "Bearer partner-key"), want: codes.OK},
+	}
+	for _, tt := range tests {
+		ctx := metadata.NewOutgoingContext(context.Background(), tt.md)
+		if _, err := client.GetRegion(ctx, &wilayahv1.GetRegionRequest{Code: "31"}); status.Code(err) != tt.want {
+			t.Errorf("%s: GetRegion returned %v, want %s", tt.name, err, tt.want)
+		}
+
+		// Streams are rejected when opened, on the first receive
+		stream, err := client.LookupRegions(ctx)
+		if err != nil {
+			t.Fatalf("%s: LookupRegions returned error: %v", tt.name, err)
+		}
+		stream.Send(&wilayahv1.GetRegionRequest{Code: "31"})
+		stream.CloseSend()
+		if _, err := stream.Recv(); status.Code(err) != tt.want {
+			t.Errorf("%s: LookupRegions returned %v, want %s", tt.name, err, tt.want)
+		}
+	}
+
+	// Health checks need no key
+	if _, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("health check returned error: %v", err)
+	}
+}
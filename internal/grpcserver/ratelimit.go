@@ -0,0 +1,88 @@
+package grpcserver
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/auth"
+	"github.com/ilmimris/wilayah-indonesia/internal/ratelimit"
+)
+
+// RateLimitOptions returns the server options applying the limits of limiter
+// to the calls to the region service. Unary calls take a token of the search
+// group, and streaming calls one of the batch group for every message they
+// receive, so a stream cannot look up more regions than separate calls.
+// Clients are identified by the label of their API key, which requires the
+// options to follow AuthOptions, and by peer address otherwise. Calls over
+// the limit fail with ResourceExhausted; they are let through when the store
+// fails.
+func RateLimitOptions(limiter *ratelimit.Limiter) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if strings.HasPrefix(info.FullMethod, regionMethodPrefix) {
+				if err := take(ctx, limiter, ratelimit.GroupSearch); err != nil {
+					return nil, err
+				}
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if strings.HasPrefix(info.FullMethod, regionMethodPrefix) {
+				ss = &limitedStream{ServerStream: ss, limiter: limiter}
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// take takes a token of group for the client of ctx.
+func take(ctx context.Context, limiter *ratelimit.Limiter, group string) error {
+	res, err := limiter.Take(ctx, group, clientKey(ctx))
+	if err != nil {
+		slog.WarnContext(ctx, "Rate limit store failed", "error", err, "group", group)
+		return nil
+	}
+	if !res.Allowed {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %d seconds", int(math.Ceil(res.RetryAfter.Seconds())))
+	}
+	return nil
+}
+
+// clientKey identifies the client of a call like ratelimit.ClientKey, by the
+// label of its API key or by the IP address of the peer.
+func clientKey(ctx context.Context) string {
+	if label := auth.Label(ctx); label != "" {
+		return "key:" + label
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "ip:"
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return "ip:" + addr
+}
+
+// limitedStream is a grpc.ServerStream taking a token of the batch group for
+// every message received.
+type limitedStream struct {
+	grpc.ServerStream
+	limiter *ratelimit.Limiter
+}
+
+func (s *limitedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return take(s.Context(), s.limiter, ratelimit.GroupBatch)
+}
@@ -0,0 +1,67 @@
+package grpcserver
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/ratelimit"
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+	wilayahv1 "github.com/ilmimris/wilayah-indonesia/proto/wilayah/v1"
+)
+
+func TestRateLimitOptions(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`
+		CREATE TABLE regions (id VARCHAR, subdistrict VARCHAR, district VARCHAR, city VARCHAR,
+			province VARCHAR, postal_code VARCHAR, full_text VARCHAR);
+		INSERT INTO regions VALUES ('31.71.01.1001', 'Gambir', 'Gambir', 'Kota Adm. Jakarta Pusat', 'DKI Jakarta', '10110', '');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create regions: %v", err)
+	}
+
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{
+		ratelimit.GroupSearch: {Rate: 0.001, Burst: 2},
+		ratelimit.GroupBatch:  {Rate: 0.001, Burst: 3},
+	})
+	client := wilayahv1.NewRegionServiceClient(serve(t, service.New(db), RateLimitOptions(limiter)...))
+	ctx := context.Background()
+
+	for i, want := range []codes.Code{codes.OK, codes.OK, codes.ResourceExhausted} {
+		if _, err := client.GetRegion(ctx, &wilayahv1.GetRegionRequest{Code: "31"}); status.Code(err) != want {
+			t.Errorf("GetRegion call %d returned %v, want %s", i+1, err, want)
+		}
+	}
+
+	// Every lookup of a stream takes a token of the batch group
+	stream, err := client.LookupRegions(ctx)
+	if err != nil {
+		t.Fatalf("LookupRegions returned error: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		stream.Send(&wilayahv1.GetRegionRequest{Code: "31"})
+	}
+	stream.CloseSend()
+	var results int
+	for {
+		_, err := stream.Recv()
+		if err != nil {
+			if status.Code(err) != codes.ResourceExhausted {
+				t.Errorf("expected ResourceExhausted once the batch limit is reached, got %v", err)
+			}
+			break
+		}
+		results++
+	}
+	if results != 3 {
+		t.Errorf("got %d lookups, want 3", results)
+	}
+}
@@ -0,0 +1,237 @@
+// Package grpcserver serves the region search over gRPC, sharing the
+// service.Service used by the HTTP API. The server also registers the
+// standard health checking and reflection services.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+	wilayahv1 "github.com/ilmimris/wilayah-indonesia/proto/wilayah/v1"
+)
+
+// errorCodes maps service error codes to gRPC status codes.
+var errorCodes = map[string]codes.Code{
+	service.ErrCodeInvalidInput:    codes.InvalidArgument,
+	service.ErrCodeNotFound:        codes.NotFound,
+	service.ErrCodeDatabaseFailure: codes.Internal,
+}
+
+// Server implements wilayahv1.RegionServiceServer on top of service.Service.
+type Server struct {
+	wilayahv1.UnimplementedRegionServiceServer
+
+	svc *service.Service
+}
+
+// New creates a new Server instance with the provided service.
+func New(svc *service.Service) *Server {
+	return &Server{
+		svc: svc,
+	}
+}
+
+// NewGRPCServer returns a grpc.Server serving the region service, health
+// checks and reflection. The health server reports the region service and
+// the server as a whole as serving.
+func NewGRPCServer(svc *service.Service, opts ...grpc.ServerOption) (*grpc.Server, *health.Server) {
+	srv := grpc.NewServer(opts...)
+	wilayahv1.RegisterRegionServiceServer(srv, New(svc))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(wilayahv1.RegionService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthServer)
+
+	reflection.Register(srv)
+	return srv, healthServer
+}
+
+// Search runs a full-text search across all region names.
+func (s *Server) Search(ctx context.Context, req *wilayahv1.SearchRequest) (*wilayahv1.SearchResponse, error) {
+	return s.search(ctx, service.SearchAll, req)
+}
+
+// SearchDistricts searches regions by district name.
+func (s *Server) SearchDistricts(ctx context.Context, req *wilayahv1.SearchRequest) (*wilayahv1.SearchResponse, error) {
+	return s.search(ctx, service.SearchDistrict, req)
+}
+
+// SearchSubdistricts searches regions by subdistrict name.
+func (s *Server) SearchSubdistricts(ctx context.Context, req *wilayahv1.SearchRequest) (*wilayahv1.SearchResponse, error) {
+	return s.search(ctx, service.SearchSubdistrict, req)
+}
+
+// SearchCities searches regions by city name.
+func (s *Server) SearchCities(ctx context.Context, req *wilayahv1.SearchRequest) (*wilayahv1.SearchResponse, error) {
+	return s.search(ctx, service.SearchCity, req)
+}
+
+// SearchProvinces searches regions by province name.
+func (s *Server) SearchProvinces(ctx context.Context, req *wilayahv1.SearchRequest) (*wilayahv1.SearchResponse, error) {
+	return s.search(ctx, service.SearchProvince, req)
+}
+
+// SearchPostalCode lists the regions with a postal code.
+func (s *Server) SearchPostalCode(ctx context.Context, req *wilayahv1.SearchPostalCodeRequest) (*wilayahv1.SearchResponse, error) {
+	response, err := s.searchPostalCode(ctx, req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return response, nil
+}
+
+// GetRegion looks up a region by code.
+func (s *Server) GetRegion(ctx context.Context, req *wilayahv1.GetRegionRequest) (*wilayahv1.Region, error) {
+	region, err := s.svc.GetByCode(ctx, req.GetCode())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toProto(*region), nil
+}
+
+// LookupRegions answers each code received on the stream with its region.
+func (s *Server) LookupRegions(stream wilayahv1.RegionService_LookupRegionsServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		response := &wilayahv1.LookupRegionResponse{Code: req.GetCode()}
+		region, err := s.svc.GetByCode(stream.Context(), req.GetCode())
+		if err != nil {
+			if service.IsError(err, service.ErrCodeDatabaseFailure) {
+				return statusError(err)
+			}
+			response.Result = &wilayahv1.LookupRegionResponse_Error{Error: lookupError(err)}
+		} else {
+			response.Result = &wilayahv1.LookupRegionResponse_Region{Region: toProto(*region)}
+		}
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+	}
+}
+
+// LookupPostalCodes answers each postal code received on the stream with its regions.
+func (s *Server) LookupPostalCodes(stream wilayahv1.RegionService_LookupPostalCodesServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		response := &wilayahv1.LookupPostalCodeResponse{PostalCode: req.GetPostalCode()}
+		page, err := s.searchPostalCode(stream.Context(), req)
+		if err != nil {
+			if service.IsError(err, service.ErrCodeDatabaseFailure) {
+				return statusError(err)
+			}
+			response.Error = lookupError(err)
+		} else {
+			response.Regions = page.Regions
+		}
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+	}
+}
+
+// search runs a paginated search of the given kind.
+func (s *Server) search(ctx context.Context, kind string, req *wilayahv1.SearchRequest) (*wilayahv1.SearchResponse, error) {
+	page, err := s.svc.SearchPage(ctx, kind, req.GetQuery(), service.SearchOptions{
+		Limit:  int(req.GetLimit()),
+		Offset: int(req.GetOffset()),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toSearchResponse(page), nil
+}
+
+// searchPostalCode runs a paginated postal code search, returning service errors.
+func (s *Server) searchPostalCode(ctx context.Context, req *wilayahv1.SearchPostalCodeRequest) (*wilayahv1.SearchResponse, error) {
+	page, err := s.svc.SearchPage(ctx, service.SearchPostalCode, req.GetPostalCode(), service.SearchOptions{
+		Limit:  int(req.GetLimit()),
+		Offset: int(req.GetOffset()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSearchResponse(page), nil
+}
+
+// toSearchResponse converts a page of regions to a SearchResponse.
+func toSearchResponse(page *service.Page[service.Region]) *wilayahv1.SearchResponse {
+	regions := make([]*wilayahv1.Region, len(page.Items))
+	for i, region := range page.Items {
+		regions[i] = toProto(region)
+	}
+	return &wilayahv1.SearchResponse{
+		Regions: regions,
+		Total:   int32(page.Total),
+		Limit:   int32(page.Limit),
+		Offset:  int32(page.Offset),
+	}
+}
+
+// toProto converts a service.Region to its protobuf message.
+func toProto(region service.Region) *wilayahv1.Region {
+	return &wilayahv1.Region{
+		Id:          region.ID,
+		Subdistrict: region.Subdistrict,
+		District:    region.District,
+		City:        region.City,
+		Province:    region.Province,
+		PostalCode:  region.PostalCode,
+		FullText:    region.FullText,
+		Latitude:    region.Latitude,
+		Longitude:   region.Longitude,
+		AreaKm2:     region.AreaKm2,
+		Population:  region.Population,
+	}
+}
+
+// lookupError converts a service error to the Error message of a stream response.
+func lookupError(err error) *wilayahv1.Error {
+	var svcErr *service.Error
+	if errors.As(err, &svcErr) {
+		return &wilayahv1.Error{Code: svcErr.Code, Message: svcErr.Message}
+	}
+	return &wilayahv1.Error{Code: "INTERNAL_ERROR", Message: "an unexpected error occurred"}
+}
+
+// statusError converts a service error to a gRPC status error. Only the
+// message of service errors is sent; database causes are logged.
+func statusError(err error) error {
+	var svcErr *service.Error
+	if !errors.As(err, &svcErr) {
+		slog.Error("gRPC request failed", "error", err)
+		return status.Error(codes.Internal, "an unexpected error occurred")
+	}
+	code, ok := errorCodes[svcErr.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+	if code == codes.Internal {
+		slog.Error("gRPC request failed", "error", err, "code", svcErr.Code)
+	}
+	return status.Error(code, svcErr.Message)
+}
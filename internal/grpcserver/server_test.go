@@ -0,0 +1,113 @@
+package grpcserver
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"testing"
+
+	_ "github.com/marcboeker/go-duckdb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+	wilayahv1 "github.com/ilmimris/wilayah-indonesia/proto/wilayah/v1"
+)
+
+func TestServer(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE regions (id VARCHAR, subdistrict VARCHAR, district VARCHAR, city VARCHAR,
+			province VARCHAR, postal_code VARCHAR, full_text VARCHAR);
+		INSERT INTO regions VALUES
+			('32.73.01.1001', 'Sarijadi', 'Sukasari', 'Kota Bandung', 'Jawa Barat', '40151', ''),
+			('32.73.02.1001', 'Hegarmanah', 'Cidadap', 'Kota Bandung', 'Jawa Barat', '40141', ''),
+			('31.71.01.1001', 'Gambir', 'Gambir', 'Kota Adm. Jakarta Pusat', 'DKI Jakarta', '10110', '');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create regions: %v", err)
+	}
+
+	conn := serve(t, service.New(db))
+	client := wilayahv1.NewRegionServiceClient(conn)
+	ctx := context.Background()
+
+	resp, err := client.SearchProvinces(ctx, &wilayahv1.SearchRequest{Query: "Jawa Barat", Limit: 1})
+	if err != nil {
+		t.Fatalf("SearchProvinces returned error: %v", err)
+	}
+	if resp.Total != 2 || len(resp.Regions) != 1 || resp.Regions[0].Id != "32.73.01.1001" {
+		t.Errorf("unexpected search response: %v", resp)
+	}
+
+	region, err := client.GetRegion(ctx, &wilayahv1.GetRegionRequest{Code: "32.73"})
+	if err != nil || region.City != "Kota Bandung" {
+		t.Errorf("GetRegion returned %v, %v", region, err)
+	}
+	if _, err := client.GetRegion(ctx, &wilayahv1.GetRegionRequest{Code: "99"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown code, got %v", err)
+	}
+	if _, err := client.SearchPostalCode(ctx, &wilayahv1.SearchPostalCodeRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a missing postal code, got %v", err)
+	}
+
+	// Bulk lookups answer every request in order, reporting failures inline
+	stream, err := client.LookupRegions(ctx)
+	if err != nil {
+		t.Fatalf("LookupRegions returned error: %v", err)
+	}
+	for _, code := range []string{"32.73.02.1001", "invalid", "31"} {
+		if err := stream.Send(&wilayahv1.GetRegionRequest{Code: code}); err != nil {
+			t.Fatalf("failed to send %s: %v", code, err)
+		}
+	}
+	stream.CloseSend()
+	var results []*wilayahv1.LookupRegionResponse
+	for {
+		result, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		results = append(results, result)
+	}
+	if len(results) != 3 || results[0].GetRegion().GetSubdistrict() != "Hegarmanah" ||
+		results[1].GetError().GetCode() != service.ErrCodeInvalidInput || results[2].GetRegion().GetProvince() != "DKI Jakarta" {
+		t.Errorf("unexpected lookup results: %v", results)
+	}
+
+	health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: wilayahv1.RegionService_ServiceDesc.ServiceName,
+	})
+	if err != nil || health.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("health check returned %v, %v", health, err)
+	}
+}
+
+// serve serves svc with opts over an in-memory listener and returns a client
+// connection to it.
+func serve(t *testing.T, svc *service.Service, opts ...grpc.ServerOption) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv, _ := NewGRPCServer(svc, opts...)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
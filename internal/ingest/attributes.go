@@ -0,0 +1,144 @@
+package ingest
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/schema"
+)
+
+// attributeNamePattern matches the characters allowed in an attribute set name.
+var attributeNamePattern = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// normalizeCodeSQL returns the SQL equivalent of NormalizeCode applied to a VARCHAR expression.
+func normalizeCodeSQL(expr string) string {
+	c := "TRIM(" + expr + ")"
+	part := func(from, length int) string {
+		return fmt.Sprintf("SUBSTRING(%s FROM %d FOR %d)", c, from, length)
+	}
+	return "CASE" +
+		" WHEN NOT regexp_matches(" + c + ", '^[0-9]+$') THEN " + c +
+		" WHEN LENGTH(" + c + ") = 4 THEN " + part(1, 2) + " || '.' || " + part(3, 2) +
+		" WHEN LENGTH(" + c + ") = 6 THEN " + part(1, 2) + " || '.' || " + part(3, 2) + " || '.' || " + part(5, 2) +
+		" WHEN LENGTH(" + c + ") = 10 THEN " + part(1, 2) + " || '.' || " + part(3, 2) + " || '.' || " + part(5, 2) + " || '.' || " + part(7, 4) +
+		" ELSE " + c + " END"
+}
+
+// AttributeSetName derives the attribute set name from a CSV file name,
+// so data/attributes/Phone Area Codes.csv becomes phone_area_codes.
+func AttributeSetName(path string) string {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return strings.Trim(attributeNamePattern.ReplaceAllString(strings.ToLower(base), "_"), "_")
+}
+
+// LoadAttributeFiles loads every CSV file in dir as an attribute set and
+// returns the names of the loaded sets. The sets named in inherit are
+// registered as inheritable. See LoadAttributeFile.
+func LoadAttributeFiles(db *sql.DB, dir string, inherit []string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attribute directory: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".csv") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		name, err := LoadAttributeFile(db, path, slices.Contains(inherit, AttributeSetName(path)))
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	for _, name := range inherit {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("inheritable attribute set %s has no file in %s", name, dir)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadAttributeFile loads a CSV file of extra region attributes into its own
+// side table and registers it in the attribute set registry read by the API.
+// The file needs a code (or kode) column holding Kemendagri codes at any
+// level; every other column becomes an attribute with a type detected by DuckDB.
+// Regions only receive the rows of their own code, unless inherit is set, in
+// which case regions without a row receive that of their closest ancestor.
+// Inherit suits values shared by every region below a code, such as phone
+// area codes, but not totals such as population or area.
+func LoadAttributeFile(db *sql.DB, path string, inherit bool) (string, error) {
+	name := AttributeSetName(path)
+	if name == "" {
+		return "", fmt.Errorf("cannot derive an attribute set name from %s", path)
+	}
+	table := schema.AttributeTablePrefix + name
+
+	// Read the header to find the code column, which must stay VARCHAR so
+	// codes such as 32.70 are not detected as numbers
+	header, err := readCSVHeader(path)
+	if err != nil {
+		return "", err
+	}
+	var codeColumn string
+	var attributes []string
+	for _, c := range header {
+		if codeColumn == "" && (strings.EqualFold(c, "code") || strings.EqualFold(c, "kode")) {
+			codeColumn = c
+			continue
+		}
+		attributes = append(attributes, c)
+	}
+	if codeColumn == "" {
+		return "", fmt.Errorf("attribute file %s has no code column", path)
+	}
+	if len(attributes) == 0 {
+		return "", fmt.Errorf("attribute file %s has no attribute columns", path)
+	}
+
+	selected := []string{normalizeCodeSQL(quoteIdent(codeColumn)) + " AS code"}
+	for _, a := range attributes {
+		selected = append(selected, quoteIdent(a))
+	}
+	source := "read_csv(" + quoteLiteral(path) + ", header = true, types = {" + quoteLiteral(codeColumn) + ": 'VARCHAR'})"
+	query := "CREATE OR REPLACE TABLE " + quoteIdent(table) + " AS SELECT " + strings.Join(selected, ", ") + " FROM " + source
+	if _, err := db.Exec(query); err != nil {
+		return "", fmt.Errorf("failed to load attribute file %s: %w", path, err)
+	}
+
+	registry := "CREATE TABLE IF NOT EXISTS " + schema.AttributeSetsTable +
+		" (name VARCHAR PRIMARY KEY, table_name VARCHAR, source VARCHAR, inherit BOOLEAN NOT NULL DEFAULT false)"
+	if _, err := db.Exec(registry); err != nil {
+		return "", fmt.Errorf("failed to create attribute registry: %w", err)
+	}
+	_, err = db.Exec("INSERT OR REPLACE INTO "+schema.AttributeSetsTable+" (name, table_name, source, inherit) VALUES (?, ?, ?, ?)",
+		name, table, filepath.Base(path), inherit)
+	if err != nil {
+		return "", fmt.Errorf("failed to register attribute set %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// readCSVHeader returns the trimmed column names of a CSV file.
+func readCSVHeader(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open attribute file: %w", err)
+	}
+	defer f.Close()
+
+	header, err := csv.NewReader(f).Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header of %s: %w", path, err)
+	}
+	return trimHeader(header), nil
+}
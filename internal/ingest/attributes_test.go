@@ -0,0 +1,82 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+func TestLoadAttributeFiles(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"Phone Area Codes.csv": "kode,area_code\n32,022\n32.70,0231\n",
+		"plates.csv":           "code,prefix\n3273,D\n",
+		"notes.txt":            "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if _, err := LoadAttributeFiles(db, dir, []string{"minimum_wages"}); err == nil {
+		t.Error("expected error for an inheritable set without a file")
+	}
+
+	names, err := LoadAttributeFiles(db, dir, []string{"phone_area_codes"})
+	if err != nil {
+		t.Fatalf("LoadAttributeFiles returned error: %v", err)
+	}
+	if len(names) != 2 || names[0] != "phone_area_codes" || names[1] != "plates" {
+		t.Fatalf("unexpected attribute sets: %v", names)
+	}
+
+	// Codes must keep their trailing zeros and undotted codes are normalized
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM attr_phone_area_codes WHERE code IN ('32', '32.70')`).Scan(&count); err != nil {
+		t.Fatalf("failed to query attribute table: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("found %d phone area code rows, want 2", count)
+	}
+
+	svc := service.New(db)
+	regions := []service.Region{{ID: "32.73.01.1001"}, {ID: "31.71"}, {ID: "32.73"}, {ID: "32.70"}}
+	if err := svc.WithAttributes(context.Background(), regions, []string{"phone_area_codes", "plates"}); err != nil {
+		t.Fatalf("WithAttributes returned error: %v", err)
+	}
+
+	// Plates only match on the exact code
+	if got, ok := regions[0].Attributes["plates"]; ok {
+		t.Errorf("plates of a region below 32.73 = %v, want none", got)
+	}
+	if got := regions[2].Attributes["plates"]["prefix"]; got != "D" {
+		t.Errorf("plates prefix = %v, want D", got)
+	}
+
+	// Phone area codes are inherited and say where from
+	if got := regions[0].Attributes["phone_area_codes"]; got["area_code"] != "022" || got[service.AttributeSourceCode] != "32" {
+		t.Errorf("inherited phone area codes = %v, want area code 022 from 32", got)
+	}
+	if got := regions[3].Attributes["phone_area_codes"]; got["area_code"] != "0231" || got[service.AttributeSourceCode] != nil {
+		t.Errorf("own phone area codes = %v, want area code 0231 without source code", got)
+	}
+	if regions[1].Attributes != nil {
+		t.Errorf("unexpected attributes for region without data: %v", regions[1].Attributes)
+	}
+
+	if err := svc.WithAttributes(context.Background(), regions, []string{"unknown"}); !service.IsError(err, service.ErrCodeInvalidInput) {
+		t.Errorf("expected invalid input error for unknown set, got %v", err)
+	}
+}
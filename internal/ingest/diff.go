@@ -0,0 +1,382 @@
+package ingest
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Change kinds reported by Diff.
+const (
+	ChangeAdded             = "added"
+	ChangeRemoved           = "removed"
+	ChangeRenamed           = "renamed"
+	ChangeReparented        = "reparented"
+	ChangeRecoded           = "recoded"
+	ChangePostalCodeChanged = "postal_code_changed"
+)
+
+// changeKinds lists the change kinds in report order.
+var changeKinds = []string{
+	ChangeAdded,
+	ChangeRemoved,
+	ChangeRenamed,
+	ChangeReparented,
+	ChangeRecoded,
+	ChangePostalCodeChanged,
+}
+
+// Snapshot identifies one dataset build to compare.
+type Snapshot struct {
+	// Path is either a regions.duckdb file or a wilayah SQL dump (*.sql).
+	Path string
+	// PostalPath is the wilayah_kodepos SQL dump loaded alongside an SQL Path. Optional.
+	PostalPath string
+}
+
+// Change describes how a single region differs between two snapshots.
+// Old fields are empty for added regions and New fields are empty for removed ones.
+type Change struct {
+	Kind          string `json:"kind"`
+	Level         string `json:"level"`
+	OldCode       string `json:"old_code,omitempty"`
+	NewCode       string `json:"new_code,omitempty"`
+	OldName       string `json:"old_name,omitempty"`
+	NewName       string `json:"new_name,omitempty"`
+	OldPostalCode string `json:"old_postal_code,omitempty"`
+	NewPostalCode string `json:"new_postal_code,omitempty"`
+}
+
+// Changelog is the result of comparing two snapshots.
+type Changelog struct {
+	Summary map[string]int `json:"summary"`
+	Changes []Change       `json:"changes"`
+}
+
+// node is a region at any level as loaded from a snapshot.
+type node struct {
+	code       string
+	name       string
+	postalCode string
+	parentName string
+}
+
+// LevelOf returns the administrative level of a Kemendagri code based on its length.
+func LevelOf(code string) string {
+	switch len(code) {
+	case 2:
+		return "province"
+	case 5:
+		return "city"
+	case 8:
+		return "district"
+	case 13:
+		return "subdistrict"
+	default:
+		return "unknown"
+	}
+}
+
+// parentOf returns the parent code of a Kemendagri code, or "" for provinces.
+func parentOf(code string) string {
+	if i := strings.LastIndex(code, "."); i > 0 {
+		return code[:i]
+	}
+	return ""
+}
+
+// Diff compares two snapshots and reports added, removed, renamed, re-parented
+// and re-coded regions as well as postal code changes.
+//
+// Regions whose code disappears and reappears elsewhere are paired by name at
+// the same level, first within a parent of the same name and then across the
+// whole dataset. Only unambiguous pairs are reported as moves; everything else
+// is listed as added or removed.
+func Diff(oldSnap, newSnap Snapshot) (*Changelog, error) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open in-memory database: %w", err)
+	}
+	defer db.Close()
+
+	oldNodes, oldHasPostal, err := loadSnapshot(db, oldSnap)
+	if err != nil {
+		return nil, err
+	}
+	newNodes, newHasPostal, err := loadSnapshot(db, newSnap)
+	if err != nil {
+		return nil, err
+	}
+	// Postal codes are only compared when both snapshots include them
+	comparePostal := oldHasPostal && newHasPostal
+
+	changelog := &Changelog{Summary: make(map[string]int)}
+	var removed, added []node
+
+	for code, o := range oldNodes {
+		n, ok := newNodes[code]
+		if !ok {
+			removed = append(removed, o)
+			continue
+		}
+		if o.name != n.name {
+			changelog.add(Change{Kind: ChangeRenamed, OldCode: code, NewCode: code, OldName: o.name, NewName: n.name})
+		}
+		if comparePostal && o.postalCode != n.postalCode {
+			changelog.add(Change{
+				Kind: ChangePostalCodeChanged, OldCode: code, NewCode: code, OldName: o.name, NewName: n.name,
+				OldPostalCode: o.postalCode, NewPostalCode: n.postalCode,
+			})
+		}
+	}
+	for code, n := range newNodes {
+		if _, ok := oldNodes[code]; !ok {
+			added = append(added, n)
+		}
+	}
+
+	// Pair codes that moved, preferring matches whose parent kept its name
+	removed, added = changelog.matchMoves(removed, added, func(n node) string {
+		return LevelOf(n.code) + "|" + normalizeName(n.name) + "|" + normalizeName(n.parentName)
+	})
+	removed, added = changelog.matchMoves(removed, added, func(n node) string {
+		return LevelOf(n.code) + "|" + normalizeName(n.name)
+	})
+
+	for _, o := range removed {
+		changelog.add(Change{Kind: ChangeRemoved, OldCode: o.code, OldName: o.name, OldPostalCode: o.postalCode})
+	}
+	for _, n := range added {
+		changelog.add(Change{Kind: ChangeAdded, NewCode: n.code, NewName: n.name, NewPostalCode: n.postalCode})
+	}
+
+	changelog.sort()
+	return changelog, nil
+}
+
+// matchMoves pairs removed and added nodes that share a unique key and
+// records them as re-parented or re-coded. It returns the unmatched nodes.
+func (l *Changelog) matchMoves(removed, added []node, key func(node) string) ([]node, []node) {
+	byKey := func(nodes []node) map[string][]int {
+		m := make(map[string][]int)
+		for i, n := range nodes {
+			m[key(n)] = append(m[key(n)], i)
+		}
+		return m
+	}
+	removedByKey, addedByKey := byKey(removed), byKey(added)
+
+	matchedRemoved := make(map[int]bool)
+	matchedAdded := make(map[int]bool)
+	for k, ri := range removedByKey {
+		ai := addedByKey[k]
+		if len(ri) != 1 || len(ai) != 1 {
+			continue
+		}
+		o, n := removed[ri[0]], added[ai[0]]
+		kind := ChangeRecoded
+		if parentOf(o.code) != parentOf(n.code) {
+			kind = ChangeReparented
+		}
+		l.add(Change{
+			Kind: kind, OldCode: o.code, NewCode: n.code, OldName: o.name, NewName: n.name,
+			OldPostalCode: o.postalCode, NewPostalCode: n.postalCode,
+		})
+		matchedRemoved[ri[0]] = true
+		matchedAdded[ai[0]] = true
+	}
+
+	filter := func(nodes []node, matched map[int]bool) []node {
+		var rest []node
+		for i, n := range nodes {
+			if !matched[i] {
+				rest = append(rest, n)
+			}
+		}
+		return rest
+	}
+	return filter(removed, matchedRemoved), filter(added, matchedAdded)
+}
+
+// add appends a change and counts it in the summary.
+func (l *Changelog) add(c Change) {
+	code := c.NewCode
+	if code == "" {
+		code = c.OldCode
+	}
+	c.Level = LevelOf(code)
+	l.Changes = append(l.Changes, c)
+	l.Summary[c.Kind]++
+}
+
+// sort orders changes by kind, then by code.
+func (l *Changelog) sort() {
+	rank := make(map[string]int, len(changeKinds))
+	for i, k := range changeKinds {
+		rank[k] = i
+	}
+	code := func(c Change) string {
+		if c.OldCode != "" {
+			return c.OldCode
+		}
+		return c.NewCode
+	}
+	sort.SliceStable(l.Changes, func(i, j int) bool {
+		a, b := l.Changes[i], l.Changes[j]
+		if a.Kind != b.Kind {
+			return rank[a.Kind] < rank[b.Kind]
+		}
+		return code(a) < code(b)
+	})
+}
+
+// normalizeName returns the comparison key for a region name.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// loadSnapshot reads every region of a snapshot, keyed by code, and reports
+// whether the snapshot carries postal codes. A region with several postal
+// codes gets them sorted and comma-separated, so that comparing snapshots
+// does not depend on the order of their rows.
+func loadSnapshot(db *sql.DB, snap Snapshot) (nodes map[string]node, hasPostal bool, err error) {
+	var query string
+	hasPostal = true
+	if strings.EqualFold(filepath.Ext(snap.Path), ".sql") {
+		if err := LoadSQLFile(db, snap.Path); err != nil {
+			return nil, false, err
+		}
+		postal := "NULL"
+		from := "wilayah AS w"
+		hasPostal = snap.PostalPath != ""
+		if hasPostal {
+			if err := LoadSQLFile(db, snap.PostalPath); err != nil {
+				return nil, false, err
+			}
+			postal = "string_agg(DISTINCT k.kodepos, ',' ORDER BY k.kodepos)"
+			from += " LEFT JOIN wilayah_kodepos AS k ON k.kode = w.kode"
+		}
+		query = `SELECT w.kode, MIN(w.nama), ` + postal + ` FROM ` + from + ` GROUP BY w.kode`
+	} else {
+		if _, err := db.Exec("ATTACH " + quoteLiteral(snap.Path) + " AS snapshot (READ_ONLY);"); err != nil {
+			return nil, false, fmt.Errorf("failed to attach %s: %w", snap.Path, err)
+		}
+		// Detach once the rows are closed, so the next snapshot can be attached
+		defer func() {
+			if _, detachErr := db.Exec("DETACH snapshot;"); detachErr != nil && err == nil {
+				err = fmt.Errorf("failed to detach %s: %w", snap.Path, detachErr)
+			}
+		}()
+		query = `
+			SELECT id, MIN(subdistrict), string_agg(DISTINCT postal_code, ',' ORDER BY postal_code) FROM snapshot.regions GROUP BY 1
+			UNION ALL
+			SELECT SUBSTRING(id FROM 1 FOR 8), MIN(district), NULL FROM snapshot.regions GROUP BY 1
+			UNION ALL
+			SELECT SUBSTRING(id FROM 1 FOR 5), MIN(city), NULL FROM snapshot.regions GROUP BY 1
+			UNION ALL
+			SELECT SUBSTRING(id FROM 1 FOR 2), MIN(province), NULL FROM snapshot.regions GROUP BY 1
+		`
+	}
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read regions from %s: %w", snap.Path, err)
+	}
+	defer rows.Close()
+
+	nodes = make(map[string]node)
+	for rows.Next() {
+		var code, name, postalCode sql.NullString
+		if err := rows.Scan(&code, &name, &postalCode); err != nil {
+			return nil, false, fmt.Errorf("failed to scan region from %s: %w", snap.Path, err)
+		}
+		nodes[code.String] = node{code: code.String, name: name.String, postalCode: postalCode.String}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to iterate regions from %s: %w", snap.Path, err)
+	}
+
+	for code, n := range nodes {
+		n.parentName = nodes[parentOf(code)].name
+		nodes[code] = n
+	}
+
+	// Drop the raw tables so the next snapshot can be loaded into the same database
+	if err := DropRawTables(db); err != nil {
+		return nil, false, err
+	}
+	return nodes, hasPostal, nil
+}
+
+// quoteLiteral quotes s as an SQL string literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// WriteJSON writes the changelog as indented JSON.
+func (l *Changelog) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+// changeColumns is the CSV header written by WriteCSV.
+var changeColumns = []string{
+	"kind", "level", "old_code", "new_code", "old_name", "new_name", "old_postal_code", "new_postal_code",
+}
+
+// WriteCSV writes one row per change.
+func (l *Changelog) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(changeColumns); err != nil {
+		return err
+	}
+	for _, c := range l.Changes {
+		record := []string{c.Kind, c.Level, c.OldCode, c.NewCode, c.OldName, c.NewName, c.OldPostalCode, c.NewPostalCode}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown writes a summary table followed by one section per change kind.
+func (l *Changelog) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Dataset changelog\n\n")
+	b.WriteString("| Change | Count |\n|--------|-------|\n")
+	for _, kind := range changeKinds {
+		fmt.Fprintf(&b, "| %s | %d |\n", kind, l.Summary[kind])
+	}
+
+	for _, kind := range changeKinds {
+		if l.Summary[kind] == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", kind)
+		b.WriteString("| Level | Old code | New code | Old name | New name | Old postal code | New postal code |\n")
+		b.WriteString("|-------|----------|----------|----------|----------|-----------------|-----------------|\n")
+		for _, c := range l.Changes {
+			if c.Kind != kind {
+				continue
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s |\n",
+				c.Level, c.OldCode, c.NewCode, escapeMarkdown(c.OldName), escapeMarkdown(c.NewName),
+				c.OldPostalCode, c.NewPostalCode)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeMarkdown escapes characters that would break a Markdown table cell.
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
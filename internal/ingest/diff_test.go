@@ -0,0 +1,122 @@
+package ingest
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeDump writes a minimal wilayah dump with the given values to dir.
+func writeDump(t *testing.T, dir, name, table, columns, values string) string {
+	t.Helper()
+	sql := "CREATE TABLE " + table + " (" + columns + ") ENGINE=MyISAM DEFAULT CHARSET=utf8mb4;\n" +
+		"INSERT INTO " + table + " VALUES\n" + values + ";\n"
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(sql), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	const regionColumns = "kode varchar(13) NOT NULL, nama varchar(100) DEFAULT NULL"
+	const postalColumns = "kode varchar(13) NOT NULL, kodepos char(5) NOT NULL"
+
+	oldSnap := Snapshot{
+		Path: writeDump(t, dir, "old.sql", "wilayah", regionColumns, `
+			('32', 'Jawa Barat'), ('32.73', 'Kota Bandung'),
+			('32.73.01', 'Sukasari'), ('32.73.02', 'Cidadap'),
+			('32.73.01.1001', 'Sarijadi'), ('32.73.01.1002', 'Sukarasa'),
+			('32.73.02.1001', 'Hegarmanah'), ('32.73.02.1002', 'Ciumbuleuit')`),
+		PostalPath: writeDump(t, dir, "old_kodepos.sql", "wilayah_kodepos", postalColumns, `
+			('32.73.01.1001', '40151'), ('32.73.01.1002', '40152'), ('32.73.02.1001', '40141'),
+			('32.73.01.1001', '40159')`),
+	}
+	newSnap := Snapshot{
+		Path: writeDump(t, dir, "new.sql", "wilayah", regionColumns, `
+			('32', 'Jawa Barat'), ('32.73', 'Kota Bandung'),
+			('32.73.01', 'Sukasari'), ('32.73.02', 'Cidadap'),
+			('32.73.01.1001', 'Sarijadi Baru'), ('32.73.01.1002', 'Sukarasa'),
+			('32.73.01.1003', 'Hegarmanah'), ('32.73.02.1003', 'Ledeng')`),
+		PostalPath: writeDump(t, dir, "new_kodepos.sql", "wilayah_kodepos", postalColumns, `
+			('32.73.01.1001', '40159'), ('32.73.01.1001', '40151'), ('32.73.01.1002', '40153'),
+			('32.73.01.1003', '40141')`),
+	}
+
+	changelog, err := Diff(oldSnap, newSnap)
+	if err != nil {
+		t.Fatalf("Diff returned error: %v", err)
+	}
+
+	want := []Change{
+		{Kind: ChangeAdded, Level: "subdistrict", NewCode: "32.73.02.1003", NewName: "Ledeng"},
+		{Kind: ChangeRemoved, Level: "subdistrict", OldCode: "32.73.02.1002", OldName: "Ciumbuleuit"},
+		{Kind: ChangeRenamed, Level: "subdistrict", OldCode: "32.73.01.1001", NewCode: "32.73.01.1001", OldName: "Sarijadi", NewName: "Sarijadi Baru"},
+		{
+			Kind: ChangeReparented, Level: "subdistrict", OldCode: "32.73.02.1001", NewCode: "32.73.01.1003",
+			OldName: "Hegarmanah", NewName: "Hegarmanah", OldPostalCode: "40141", NewPostalCode: "40141",
+		},
+		{
+			Kind: ChangePostalCodeChanged, Level: "subdistrict", OldCode: "32.73.01.1002", NewCode: "32.73.01.1002",
+			OldName: "Sukarasa", NewName: "Sukarasa", OldPostalCode: "40152", NewPostalCode: "40153",
+		},
+	}
+	if len(changelog.Changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changelog.Changes), len(want), changelog.Changes)
+	}
+	for i, c := range changelog.Changes {
+		if c != want[i] {
+			t.Errorf("change %d:\n got %+v\nwant %+v", i, c, want[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := changelog.WriteMarkdown(&buf); err != nil {
+		t.Fatalf("WriteMarkdown returned error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| reparented | 1 |") {
+		t.Errorf("markdown summary missing reparented count:\n%s", buf.String())
+	}
+}
+
+func TestDiffDatabases(t *testing.T) {
+	dir := t.TempDir()
+	writeDB := func(name, values string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		db, err := sql.Open("duckdb", path)
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", name, err)
+		}
+		defer db.Close()
+		_, err = db.Exec(`CREATE TABLE regions (id VARCHAR, subdistrict VARCHAR, district VARCHAR, city VARCHAR,
+			province VARCHAR, postal_code VARCHAR, full_text VARCHAR); INSERT INTO regions VALUES ` + values)
+		if err != nil {
+			t.Fatalf("failed to create regions in %s: %v", name, err)
+		}
+		return path
+	}
+
+	// Regions with several postal codes have a row for each, in any order
+	oldPath := writeDB("old.duckdb", `
+		('32.73.01.1001', 'Sarijadi', 'Sukasari', 'Kota Bandung', 'Jawa Barat', '40151', ''),
+		('32.73.01.1001', 'Sarijadi', 'Sukasari', 'Kota Bandung', 'Jawa Barat', '40159', '')`)
+	newPath := writeDB("new.duckdb", `
+		('32.73.01.1001', 'Sarijadi', 'Sukasari', 'Kota Bandung', 'Jawa Barat', '40159', ''),
+		('32.73.01.1001', 'Sarijadi', 'Sukasari', 'Kota Bandung', 'Jawa Barat', '40151', ''),
+		('32.73.01.1002', 'Sukarasa', 'Sukasari', 'Kota Bandung', 'Jawa Barat', '40152', '')`)
+
+	// The second snapshot is attached under the alias of the first
+	changelog, err := Diff(Snapshot{Path: oldPath}, Snapshot{Path: newPath})
+	if err != nil {
+		t.Fatalf("Diff returned error: %v", err)
+	}
+	want := []Change{{Kind: ChangeAdded, Level: "subdistrict", NewCode: "32.73.01.1002", NewName: "Sukarasa", NewPostalCode: "40152"}}
+	if len(changelog.Changes) != 1 || changelog.Changes[0] != want[0] {
+		t.Errorf("got changes %+v, want %+v", changelog.Changes, want)
+	}
+}
@@ -0,0 +1,228 @@
+package ingest
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Export formats supported by Export.
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+	FormatXLSX    = "xlsx"
+)
+
+// ExportFormats lists the supported export formats.
+var ExportFormats = []string{FormatCSV, FormatNDJSON, FormatParquet, FormatXLSX}
+
+// codePattern matches a dotted Kemendagri code prefix such as 32 or 32.73.
+var codePattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	// Table is the table to export. Defaults to regions.
+	Table string
+	// Columns restricts the exported columns. Defaults to every column of the table.
+	Columns []string
+	// Province keeps only rows under this province code, e.g. 32.
+	Province string
+	// City keeps only rows under this city code, e.g. 32.73.
+	City string
+	// Format is one of ExportFormats.
+	Format string
+}
+
+// Export writes a table to path in the requested format and returns the
+// number of rows written. CSV, NDJSON and Parquet are written by DuckDB's
+// COPY; XLSX is streamed row by row.
+func Export(db *sql.DB, path string, opts ExportOptions) (int64, error) {
+	if opts.Table == "" {
+		opts.Table = "regions"
+	}
+
+	query, err := exportQuery(db, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var copyOptions string
+	switch opts.Format {
+	case FormatCSV:
+		copyOptions = "FORMAT csv, HEADER"
+	case FormatNDJSON:
+		copyOptions = "FORMAT json"
+	case FormatParquet:
+		copyOptions = "FORMAT parquet"
+	case FormatXLSX:
+		return exportXLSX(db, path, query, opts.Table)
+	default:
+		return 0, fmt.Errorf("unsupported export format %q (supported: %s)", opts.Format, strings.Join(ExportFormats, ", "))
+	}
+
+	result, err := db.Exec("COPY (" + query + ") TO " + quoteLiteral(path) + " (" + copyOptions + ")")
+	if err != nil {
+		return 0, fmt.Errorf("failed to export %s: %w", opts.Table, err)
+	}
+	return result.RowsAffected()
+}
+
+// exportQuery builds the SELECT statement for an export after checking the
+// table, columns and filters against the database schema.
+func exportQuery(db *sql.DB, opts ExportOptions) (string, error) {
+	available, err := tableColumns(db, opts.Table)
+	if err != nil {
+		return "", err
+	}
+	if len(available) == 0 {
+		return "", fmt.Errorf("table %q does not exist", opts.Table)
+	}
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = available
+	}
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		if !contains(available, c) {
+			return "", fmt.Errorf("table %s has no column %q (available: %s)", opts.Table, c, strings.Join(available, ", "))
+		}
+		quoted[i] = quoteIdent(c)
+	}
+
+	// Region codes live in id for regions and in kode or code for the other tables
+	codeColumn := ""
+	for _, c := range []string{"id", "kode", "code"} {
+		if contains(available, c) {
+			codeColumn = c
+			break
+		}
+	}
+
+	var where []string
+	for _, prefix := range []string{opts.Province, opts.City} {
+		if prefix == "" {
+			continue
+		}
+		if !codePattern.MatchString(prefix) {
+			return "", fmt.Errorf("invalid region code %q", prefix)
+		}
+		if codeColumn == "" {
+			return "", fmt.Errorf("table %s has no code column to filter on", opts.Table)
+		}
+		col := quoteIdent(codeColumn)
+		where = append(where, "("+col+" = "+quoteLiteral(prefix)+" OR "+col+" LIKE "+quoteLiteral(prefix+".%")+")")
+	}
+
+	query := "SELECT " + strings.Join(quoted, ", ") + " FROM " + quoteIdent(opts.Table)
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	if codeColumn != "" {
+		query += " ORDER BY " + quoteIdent(codeColumn)
+	}
+	return query, nil
+}
+
+// tableColumns returns the column names of a table in the main schema, in order.
+func tableColumns(db *sql.DB, table string) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_schema = 'main' AND table_name = ?
+		ORDER BY ordinal_position`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan column of %s: %w", table, err)
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
+
+// exportXLSX streams the query result into a single worksheet named after the table.
+func exportXLSX(db *sql.DB, path, query, table string) (int64, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to export %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read export columns: %w", err)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", table); err != nil {
+		return 0, err
+	}
+	sw, err := f.NewStreamWriter(table)
+	if err != nil {
+		return 0, err
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, c := range columns {
+		header[i] = c
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return 0, err
+	}
+
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	var n int64
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return n, fmt.Errorf("failed to scan export row: %w", err)
+		}
+		cell, err := excelize.CoordinatesToCellName(1, int(n)+2)
+		if err != nil {
+			return n, err
+		}
+		row := make([]interface{}, len(values))
+		copy(row, values)
+		if err := sw.SetRow(cell, row); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return n, fmt.Errorf("failed to iterate export rows: %w", err)
+	}
+
+	if err := sw.Flush(); err != nil {
+		return n, err
+	}
+	if err := f.SaveAs(path); err != nil {
+		return n, fmt.Errorf("failed to save %s: %w", path, err)
+	}
+	return n, nil
+}
+
+// contains reports whether list holds s.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package ingest
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+func TestExport(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE regions (id VARCHAR, subdistrict VARCHAR, postal_code VARCHAR);
+		INSERT INTO regions VALUES
+			('32.73.01.1001', 'Sarijadi', '40151'),
+			('32.74.01.1001', 'Harjamukti', '45143'),
+			('31.71.01.1001', 'Gambir', '10110');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create regions: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "regions.csv")
+	n, err := Export(db, path, ExportOptions{
+		Columns:  []string{"id", "postal_code"},
+		Province: "32",
+		City:     "32.73",
+		Format:   FormatCSV,
+	})
+	if err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("exported %d rows, want 1", n)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	if want := "id,postal_code\n32.73.01.1001,40151\n"; string(data) != want {
+		t.Errorf("unexpected export:\n%s\nwant:\n%s", data, want)
+	}
+
+	if _, err := Export(db, path, ExportOptions{Columns: []string{"full_text"}, Format: FormatCSV}); err == nil {
+		t.Error("expected error for unknown column")
+	}
+	if _, err := Export(db, path, ExportOptions{Province: "32' OR 1=1", Format: FormatCSV}); err == nil {
+		t.Error("expected error for invalid province code")
+	}
+}
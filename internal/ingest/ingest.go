@@ -0,0 +1,95 @@
+// Package ingest builds the regions DuckDB database from the upstream
+// cahyadsn/wilayah SQL dumps.
+package ingest
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/schema"
+)
+
+// Raw table names created by the upstream SQL dumps.
+const (
+	RawRegionsTable     = "wilayah"
+	RawPostalCodesTable = "wilayah_kodepos"
+)
+
+// transformationQuery denormalizes the raw wilayah tables into the final regions table.
+// Using LEFT JOIN on postal codes to maintain backward compatibility - postal code will be NULL if not available.
+const transformationQuery = `
+CREATE OR REPLACE TABLE regions AS
+SELECT
+	   sub.kode AS id,
+	   sub.nama AS subdistrict,
+	   dist.nama AS district,
+	   city.nama AS city,
+	   prov.nama AS province,
+	   kodepos.kodepos AS postal_code,
+	   LOWER(prov.nama || ' ' || city.nama || ' ' || dist.nama || ' ' || sub.nama) AS full_text
+FROM
+	   wilayah AS sub
+JOIN wilayah AS dist ON dist.kode = SUBSTRING(sub.kode FROM 1 FOR 8)
+JOIN wilayah AS city ON city.kode = SUBSTRING(sub.kode FROM 1 FOR 5)
+JOIN wilayah AS prov ON prov.kode = SUBSTRING(sub.kode FROM 1 FOR 2)
+LEFT JOIN wilayah_kodepos AS kodepos ON kodepos.kode = sub.kode
+WHERE
+	   LENGTH(sub.kode) = 13;
+`
+
+// LoadSQLFile streams a MySQL dump from path into db. Each CREATE TABLE
+// statement replaces the table of the same name and rows are inserted in
+// batches of DefaultBatchSize.
+func LoadSQLFile(db *sql.DB, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read SQL file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return LoadSQL(db, f, path, DefaultBatchSize)
+}
+
+// BuildRegions creates the denormalized regions table from the raw tables.
+func BuildRegions(db *sql.DB) error {
+	if _, err := db.Exec(transformationQuery); err != nil {
+		return fmt.Errorf("failed to execute transformation query: %w", err)
+	}
+	return nil
+}
+
+// DropRawTables removes the raw upstream tables to keep the database file small.
+func DropRawTables(db *sql.DB) error {
+	for _, table := range []string{RawRegionsTable, RawPostalCodesTable, RawStatsTable, RawBoundariesTable, RawIslandsTable} {
+		if _, err := db.Exec("DROP TABLE IF EXISTS " + table + ";"); err != nil {
+			return fmt.Errorf("failed to drop %s table: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// CreateSearchIndex installs the FTS extension and indexes the full_text
+// column of regions, and of islands when that table was built.
+func CreateSearchIndex(db *sql.DB) error {
+	if _, err := db.Exec("INSTALL fts;"); err != nil {
+		return fmt.Errorf("failed to install FTS extension: %w", err)
+	}
+	if _, err := db.Exec("LOAD fts;"); err != nil {
+		return fmt.Errorf("failed to load FTS extension: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA create_fts_index('regions', 'id', 'full_text');"); err != nil {
+		return fmt.Errorf("failed to create FTS index: %w", err)
+	}
+
+	columns, err := tableColumns(db, schema.IslandsTable)
+	if err != nil {
+		return err
+	}
+	if len(columns) > 0 {
+		if _, err := db.Exec("PRAGMA create_fts_index('islands', 'id', 'full_text');"); err != nil {
+			return fmt.Errorf("failed to create islands FTS index: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package ingest
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/schema"
+)
+
+// RawIslandsTable is the raw table created by the optional upstream island dump.
+const RawIslandsTable = "pulau"
+
+// islandsQuery denormalizes the raw island table into the islands table. An
+// island code such as 11.01.40001 starts with the code of its city, whose
+// names are taken from the regions table.
+const islandsQuery = `
+CREATE OR REPLACE TABLE islands AS
+SELECT
+	   TRIM(p.kode) AS id,
+	   TRIM(p.nama) AS name,
+	   SUBSTRING(TRIM(p.kode) FROM 1 FOR 5) AS city_code,
+	   COALESCE(c.city, '') AS city,
+	   COALESCE(c.province, '') AS province,
+	   %s AS latitude,
+	   %s AS longitude,
+	   LOWER(TRIM(p.nama) || ' ' || COALESCE(c.city, '') || ' ' || COALESCE(c.province, '')) AS full_text
+FROM
+	   pulau AS p
+LEFT JOIN (
+	   SELECT SUBSTRING(id FROM 1 FOR 5) AS code, MIN(city) AS city, MIN(province) AS province
+	   FROM regions
+	   GROUP BY code
+) AS c ON c.code = SUBSTRING(TRIM(p.kode) FROM 1 FOR 5)
+ORDER BY id;
+`
+
+// BuildIslands creates the islands table from the raw pulau table and the
+// regions table, which must be built first. Without a pulau table any stale
+// islands table is dropped.
+func BuildIslands(db *sql.DB) error {
+	columns, err := tableColumns(db, RawIslandsTable)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		if _, err := db.Exec("DROP TABLE IF EXISTS " + schema.IslandsTable); err != nil {
+			return fmt.Errorf("failed to drop %s table: %w", schema.IslandsTable, err)
+		}
+		return nil
+	}
+
+	available := make(map[string]bool)
+	for _, c := range columns {
+		available[strings.ToLower(c)] = true
+	}
+	if !available["kode"] || !available["nama"] {
+		return fmt.Errorf("table %s needs kode and nama columns", RawIslandsTable)
+	}
+	coordinate := func(name string) string {
+		if available[name] {
+			return "TRY_CAST(p." + name + " AS DOUBLE)"
+		}
+		return "CAST(NULL AS DOUBLE)"
+	}
+
+	if _, err := db.Exec(fmt.Sprintf(islandsQuery, coordinate("lat"), coordinate("lng"))); err != nil {
+		return fmt.Errorf("failed to build islands: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+func TestBuildIslands(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE regions (id VARCHAR, subdistrict VARCHAR, district VARCHAR, city VARCHAR, province VARCHAR);
+		INSERT INTO regions VALUES
+			('31.01.01.1001', 'Pulau Panggang', 'Kepulauan Seribu Utara', 'Kab. Adm. Kepulauan Seribu', 'DKI Jakarta');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create regions: %v", err)
+	}
+
+	path := writeDump(t, t.TempDir(), "pulau.sql", RawIslandsTable,
+		"kode varchar(11) NOT NULL, nama varchar(100), lat double, lng double",
+		"('31.01.40002',' Pulau Pramuka ',-5.74,106.61),('31.01.40001','Pulau Panggang',-5.74,106.60),('32.73.40001','Pulau Lain',NULL,NULL)")
+	if err := LoadSQLFile(db, path); err != nil {
+		t.Fatalf("LoadSQLFile returned error: %v", err)
+	}
+	if err := BuildIslands(db); err != nil {
+		t.Fatalf("BuildIslands returned error: %v", err)
+	}
+
+	svc := service.New(db)
+	islands, err := svc.IslandsByCity(context.Background(), "31.01")
+	if err != nil {
+		t.Fatalf("IslandsByCity returned error: %v", err)
+	}
+	if len(islands) != 2 {
+		t.Fatalf("found %d islands, want 2", len(islands))
+	}
+	first := islands[0]
+	if first.Name != "Pulau Panggang" || first.City != "Kab. Adm. Kepulauan Seribu" || first.Latitude == nil {
+		t.Errorf("unexpected first island: %+v", first)
+	}
+	if islands[1].Name != "Pulau Pramuka" {
+		t.Errorf("island name not trimmed: %q", islands[1].Name)
+	}
+
+	if _, err := svc.IslandsByCity(context.Background(), "99.99"); !service.IsError(err, service.ErrCodeNotFound) {
+		t.Errorf("expected not found error for an unknown city, got %v", err)
+	}
+	if _, err := svc.IslandsByCity(context.Background(), "31"); !service.IsError(err, service.ErrCodeInvalidInput) {
+		t.Errorf("expected invalid input error for a province code, got %v", err)
+	}
+}
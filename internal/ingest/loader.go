@@ -0,0 +1,165 @@
+package ingest
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultBatchSize is the number of rows inserted per statement when loading a dump.
+const DefaultBatchSize = 1000
+
+// LoadSQL streams a MySQL dump from r into db inside a single transaction.
+// name is used in error messages. Only CREATE TABLE and INSERT statements are
+// executed; MySQL keys, table options and session statements are dropped.
+func LoadSQL(db *sql.DB, r io.Reader, name string, batchSize int) error {
+	return loadSQL(db, r, name, batchSize, false)
+}
+
+// loadSQL implements LoadSQL. With appendRows set, tables that already exist
+// are kept and the rows of the dump are appended to them.
+func loadSQL(db *sql.DB, r io.Reader, name string, batchSize int, appendRows bool) error {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	loader := &dumpLoader{tx: tx, file: name, batchSize: batchSize, appendRows: appendRows}
+	if err := parseDump(newLexer(r, name), loader); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit %s: %w", name, err)
+	}
+	return nil
+}
+
+// dumpLoader is a dumpHandler that creates tables in DuckDB and inserts rows in batches.
+type dumpLoader struct {
+	tx         *sql.Tx
+	file       string
+	batchSize  int
+	appendRows bool
+
+	// Pending rows of the current batch, flattened into args
+	table     string
+	columns   []string
+	width     int
+	args      []interface{}
+	rows      int
+	firstLine int
+}
+
+// CreateTable creates or replaces the table with DuckDB column types. When
+// appending, an existing table is kept as is.
+func (d *dumpLoader) CreateTable(table string, columns []column, line int) error {
+	defs := make([]string, len(columns))
+	for i, c := range columns {
+		defs[i] = quoteIdent(c.name) + " " + duckDBType(c.dataType)
+	}
+	create := "CREATE OR REPLACE TABLE "
+	if d.appendRows {
+		create = "CREATE TABLE IF NOT EXISTS "
+	}
+	query := create + quoteIdent(table) + " (" + strings.Join(defs, ", ") + ")"
+	if _, err := d.tx.Exec(query); err != nil {
+		return fmt.Errorf("%s:%d: failed to create table %s: %w", d.file, line, table, err)
+	}
+	return nil
+}
+
+// InsertRow adds a row to the current batch, flushing it when full or when
+// the row targets a different table or column list.
+func (d *dumpLoader) InsertRow(table string, columns []string, values []interface{}, line int) error {
+	if d.rows > 0 && (table != d.table || len(values) != d.width || !equalColumns(columns, d.columns)) {
+		if err := d.flush(); err != nil {
+			return err
+		}
+	}
+	if d.rows == 0 {
+		d.table, d.columns, d.width, d.firstLine = table, columns, len(values), line
+	}
+
+	d.args = append(d.args, values...)
+	d.rows++
+	if d.rows >= d.batchSize {
+		return d.flush()
+	}
+	return nil
+}
+
+// EndInsert flushes the rows of the finished INSERT statement.
+func (d *dumpLoader) EndInsert(table string, line int) error {
+	return d.flush()
+}
+
+// flush inserts the pending rows with one parameterized statement.
+func (d *dumpLoader) flush() error {
+	if d.rows == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	b.WriteString(quoteIdent(d.table))
+	if d.columns != nil {
+		quoted := make([]string, len(d.columns))
+		for i, c := range d.columns {
+			quoted[i] = quoteIdent(c)
+		}
+		b.WriteString(" (" + strings.Join(quoted, ", ") + ")")
+	}
+	b.WriteString(" VALUES ")
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", d.width), ", ") + ")"
+	for i := 0; i < d.rows; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(row)
+	}
+
+	if _, err := d.tx.Exec(b.String(), d.args...); err != nil {
+		return fmt.Errorf("%s:%d: failed to insert %d rows into %s: %w", d.file, d.firstLine, d.rows, d.table, err)
+	}
+
+	d.args = d.args[:0]
+	d.rows = 0
+	return nil
+}
+
+// equalColumns reports whether two column lists are identical.
+func equalColumns(a, b []string) bool {
+	if len(a) != len(b) || (a == nil) != (b == nil) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// duckDBType maps a MySQL column type to the DuckDB type used for the raw tables.
+// Anything that is not numeric is stored as VARCHAR.
+func duckDBType(mysqlType string) string {
+	switch strings.ToLower(mysqlType) {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		return "BIGINT"
+	case "decimal", "numeric", "float", "double", "real":
+		return "DOUBLE"
+	default:
+		return "VARCHAR"
+	}
+}
+
+// quoteIdent quotes s as an SQL identifier.
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,31 @@
+package ingest
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/schema"
+)
+
+// WriteMetadata records the dataset version and build time in the metadata
+// table read by the API, and returns the version. It must run after BuildRegions.
+func WriteMetadata(db *sql.DB, builtAt time.Time) (string, error) {
+	var version sql.NullString
+	if err := db.QueryRow(schema.DatasetVersionQuery).Scan(&version); err != nil {
+		return "", fmt.Errorf("failed to compute dataset version: %w", err)
+	}
+
+	query := "CREATE OR REPLACE TABLE " + schema.MetadataTable + " (key VARCHAR PRIMARY KEY, value VARCHAR)"
+	if _, err := db.Exec(query); err != nil {
+		return "", fmt.Errorf("failed to create metadata table: %w", err)
+	}
+	_, err := db.Exec("INSERT INTO "+schema.MetadataTable+" VALUES (?, ?), (?, ?)",
+		schema.MetadataVersion, version.String,
+		schema.MetadataBuiltAt, builtAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return version.String, nil
+}
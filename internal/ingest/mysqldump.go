@@ -0,0 +1,626 @@
+package ingest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError reports a problem in a SQL dump together with the line it occurred on.
+type SyntaxError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+// Error returns the error message prefixed with the file name and line number.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokEOF         tokenKind = iota
+	tokWord                  // keyword, bare identifier or unquoted number
+	tokQuotedIdent           // `identifier`
+	tokString                // 'text' or "text"
+	tokPunct                 // single punctuation character such as ( ) , ;
+)
+
+// token is a lexical element of a MySQL dump.
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// is reports whether t is the given keyword or punctuation, ignoring case.
+func (t token) is(s string) bool {
+	return (t.kind == tokWord || t.kind == tokPunct) && strings.EqualFold(t.text, s)
+}
+
+// lexer splits a MySQL dump into tokens while reading it incrementally,
+// skipping whitespace and comments.
+type lexer struct {
+	r    *bufio.Reader
+	file string
+	line int
+	peek *token
+}
+
+func newLexer(r io.Reader, file string) *lexer {
+	return &lexer{r: bufio.NewReader(r), file: file, line: 1}
+}
+
+// errorf returns a SyntaxError at the given line.
+func (l *lexer) errorf(line int, format string, args ...interface{}) error {
+	return &SyntaxError{File: l.file, Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// readRune reads the next rune, counting lines. It returns 0 at EOF.
+func (l *lexer) readRune() (rune, error) {
+	r, _, err := l.r.ReadRune()
+	if err == io.EOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if r == '\n' {
+		l.line++
+	}
+	return r, nil
+}
+
+// unreadRune pushes back the last rune read.
+func (l *lexer) unreadRune(r rune) {
+	if r == 0 {
+		return
+	}
+	l.r.UnreadRune()
+	if r == '\n' {
+		l.line--
+	}
+}
+
+// peekRune returns the next rune without consuming it.
+func (l *lexer) peekRune() rune {
+	r, _, err := l.r.ReadRune()
+	if err != nil {
+		return 0
+	}
+	l.r.UnreadRune()
+	return r
+}
+
+// Peek returns the next token without consuming it.
+func (l *lexer) Peek() (token, error) {
+	if l.peek == nil {
+		t, err := l.scan()
+		if err != nil {
+			return token{}, err
+		}
+		l.peek = &t
+	}
+	return *l.peek, nil
+}
+
+// Next consumes and returns the next token.
+func (l *lexer) Next() (token, error) {
+	t, err := l.Peek()
+	l.peek = nil
+	return t, err
+}
+
+// scan reads the next token from the input.
+func (l *lexer) scan() (token, error) {
+	for {
+		r, err := l.readRune()
+		if err != nil {
+			return token{}, err
+		}
+		line := l.line
+
+		switch {
+		case r == 0:
+			return token{kind: tokEOF, line: line}, nil
+		case unicode.IsSpace(r):
+			continue
+		case r == '#':
+			if err := l.skipLine(); err != nil {
+				return token{}, err
+			}
+			continue
+		case r == '-' && l.peekRune() == '-':
+			if err := l.skipLine(); err != nil {
+				return token{}, err
+			}
+			continue
+		case r == '/' && l.peekRune() == '*':
+			l.readRune()
+			// MySQL conditional comments (/*!40101 ... */) only carry session settings
+			if err := l.skipBlockComment(line); err != nil {
+				return token{}, err
+			}
+			continue
+		case r == '`':
+			text, err := l.scanQuoted('`', line)
+			return token{kind: tokQuotedIdent, text: text, line: line}, err
+		case r == '\'' || r == '"':
+			text, err := l.scanQuoted(r, line)
+			return token{kind: tokString, text: text, line: line}, err
+		case isWordRune(r):
+			var b strings.Builder
+			b.WriteRune(r)
+			for {
+				next, err := l.readRune()
+				if err != nil {
+					return token{}, err
+				}
+				if !isWordRune(next) {
+					l.unreadRune(next)
+					break
+				}
+				b.WriteRune(next)
+			}
+			return token{kind: tokWord, text: b.String(), line: line}, nil
+		default:
+			return token{kind: tokPunct, text: string(r), line: line}, nil
+		}
+	}
+}
+
+// isWordRune reports whether r can be part of a bare word or number.
+func isWordRune(r rune) bool {
+	return r == '_' || r == '$' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// skipLine consumes input up to and including the next newline.
+func (l *lexer) skipLine() error {
+	for {
+		r, err := l.readRune()
+		if err != nil || r == 0 || r == '\n' {
+			return err
+		}
+	}
+}
+
+// skipBlockComment consumes input up to and including the closing */.
+func (l *lexer) skipBlockComment(line int) error {
+	var prev rune
+	for {
+		r, err := l.readRune()
+		if err != nil {
+			return err
+		}
+		if r == 0 {
+			return l.errorf(line, "unterminated comment")
+		}
+		if prev == '*' && r == '/' {
+			return nil
+		}
+		prev = r
+	}
+}
+
+// scanQuoted reads a quoted string or identifier whose opening quote has been consumed.
+// Doubled quotes are unescaped, and for strings so are MySQL backslash escapes.
+func (l *lexer) scanQuoted(quote rune, line int) (string, error) {
+	var b strings.Builder
+	for {
+		r, err := l.readRune()
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case r == 0:
+			return "", l.errorf(line, "unterminated quoted text")
+		case r == quote:
+			if l.peekRune() != quote {
+				return b.String(), nil
+			}
+			l.readRune()
+			b.WriteRune(quote)
+		case r == '\\' && quote != '`':
+			next, err := l.readRune()
+			if err != nil {
+				return "", err
+			}
+			if next == 0 {
+				return "", l.errorf(line, "unterminated quoted text")
+			}
+			b.WriteString(unescape(next))
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// unescape returns the character represented by a MySQL backslash escape.
+func unescape(r rune) string {
+	switch r {
+	case '0':
+		return "\x00"
+	case 'b':
+		return "\b"
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case 'Z':
+		return "\x1a"
+	case '%', '_':
+		// Kept escaped, as MySQL does outside of LIKE patterns
+		return `\` + string(r)
+	default:
+		return string(r)
+	}
+}
+
+// column is a column definition from a CREATE TABLE statement.
+type column struct {
+	name     string
+	dataType string
+}
+
+// dumpHandler receives the tables and rows found in a MySQL dump.
+type dumpHandler interface {
+	// CreateTable is called for every CREATE TABLE statement.
+	CreateTable(table string, columns []column, line int) error
+	// InsertRow is called for every row of an INSERT statement.
+	// A nil value represents NULL. columns is nil when the statement has no column list.
+	InsertRow(table string, columns []string, values []interface{}, line int) error
+	// EndInsert is called after the last row of an INSERT statement.
+	EndInsert(table string, line int) error
+}
+
+// parseDump streams the statements of a MySQL dump to h. CREATE TABLE and
+// INSERT statements are reported; everything else (SET, LOCK TABLES, DROP,
+// CREATE INDEX, comments, ...) is skipped.
+func parseDump(l *lexer, h dumpHandler) error {
+	for {
+		t, err := l.Next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case t.kind == tokEOF:
+			return nil
+		case t.is(";"):
+			continue
+		case t.is("CREATE"):
+			next, err := l.Peek()
+			if err != nil {
+				return err
+			}
+			if next.is("TABLE") {
+				l.Next()
+				err = parseCreateTable(l, h)
+			} else {
+				err = skipStatement(l)
+			}
+			if err != nil {
+				return err
+			}
+		case t.is("INSERT") || t.is("REPLACE"):
+			if err := parseInsert(l, h); err != nil {
+				return err
+			}
+		default:
+			if err := skipStatement(l); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// skipStatement consumes tokens up to and including the terminating semicolon.
+func skipStatement(l *lexer) error {
+	for {
+		t, err := l.Next()
+		if err != nil {
+			return err
+		}
+		if t.kind == tokEOF || t.is(";") {
+			return nil
+		}
+	}
+}
+
+// expect consumes the next token and fails unless it is s.
+func expect(l *lexer, s string) (token, error) {
+	t, err := l.Next()
+	if err != nil {
+		return t, err
+	}
+	if !t.is(s) {
+		return t, l.errorf(t.line, "expected %q, found %q", s, t.text)
+	}
+	return t, nil
+}
+
+// parseName reads a possibly qualified table or column name and returns its last part.
+func parseName(l *lexer) (string, error) {
+	t, err := l.Next()
+	if err != nil {
+		return "", err
+	}
+	if t.kind != tokWord && t.kind != tokQuotedIdent {
+		return "", l.errorf(t.line, "expected a name, found %q", t.text)
+	}
+	name := t.text
+	if t.kind == tokWord {
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+	}
+	// `schema`.`table`
+	for {
+		next, err := l.Peek()
+		if err != nil {
+			return "", err
+		}
+		if !next.is(".") && !(next.kind == tokWord && strings.HasPrefix(next.text, ".")) {
+			return name, nil
+		}
+		l.Next()
+		if next.text != "." {
+			name = strings.TrimPrefix(next.text, ".")
+			continue
+		}
+		part, err := l.Next()
+		if err != nil {
+			return "", err
+		}
+		name = part.text
+	}
+}
+
+// tableConstraints are the keywords that start a non-column entry of a table definition.
+var tableConstraints = map[string]bool{
+	"PRIMARY": true, "KEY": true, "INDEX": true, "UNIQUE": true, "CONSTRAINT": true,
+	"FULLTEXT": true, "SPATIAL": true, "FOREIGN": true, "CHECK": true,
+}
+
+// parseCreateTable reads the column definitions of a CREATE TABLE statement.
+// Keys, constraints and table options are ignored.
+func parseCreateTable(l *lexer, h dumpHandler) error {
+	next, err := l.Peek()
+	if err != nil {
+		return err
+	}
+	if next.is("IF") {
+		for _, kw := range []string{"IF", "NOT", "EXISTS"} {
+			if _, err := expect(l, kw); err != nil {
+				return err
+			}
+		}
+	}
+
+	table, err := parseName(l)
+	if err != nil {
+		return err
+	}
+	open, err := expect(l, "(")
+	if err != nil {
+		return err
+	}
+
+	var columns []column
+	for {
+		t, err := l.Next()
+		if err != nil {
+			return err
+		}
+		if t.kind == tokEOF {
+			return l.errorf(open.line, "unterminated definition of table %s", table)
+		}
+
+		isColumn := t.kind == tokQuotedIdent || (t.kind == tokWord && !tableConstraints[strings.ToUpper(t.text)])
+		if isColumn {
+			typ, err := l.Next()
+			if err != nil {
+				return err
+			}
+			if typ.kind != tokWord {
+				return l.errorf(typ.line, "expected a type for column %s, found %q", t.text, typ.text)
+			}
+			columns = append(columns, column{name: t.text, dataType: typ.text})
+		}
+
+		// Skip the rest of the entry up to the next top-level comma or the closing parenthesis
+		end, err := skipEntry(l)
+		if err != nil {
+			return err
+		}
+		if end.is(")") {
+			break
+		}
+	}
+
+	if len(columns) == 0 {
+		return l.errorf(open.line, "table %s has no columns", table)
+	}
+	if err := h.CreateTable(table, columns, open.line); err != nil {
+		return err
+	}
+	// Table options such as ENGINE=... up to the semicolon
+	return skipStatement(l)
+}
+
+// skipEntry consumes tokens up to a comma or closing parenthesis at the current
+// nesting level and returns that token.
+func skipEntry(l *lexer) (token, error) {
+	depth := 0
+	for {
+		t, err := l.Next()
+		if err != nil {
+			return t, err
+		}
+		switch {
+		case t.kind == tokEOF:
+			return t, l.errorf(t.line, "unexpected end of input")
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			if depth == 0 {
+				return t, nil
+			}
+			depth--
+		case t.is(",") && depth == 0:
+			return t, nil
+		}
+	}
+}
+
+// parseInsert reads an INSERT [IGNORE] INTO table [(columns)] VALUES (...), ...; statement
+// and passes each row to h as soon as it is read.
+func parseInsert(l *lexer, h dumpHandler) error {
+	t, err := l.Next()
+	if err != nil {
+		return err
+	}
+	for t.is("IGNORE") || t.is("LOW_PRIORITY") || t.is("DELAYED") || t.is("HIGH_PRIORITY") {
+		if t, err = l.Next(); err != nil {
+			return err
+		}
+	}
+	if !t.is("INTO") {
+		return l.errorf(t.line, "expected INTO, found %q", t.text)
+	}
+
+	table, err := parseName(l)
+	if err != nil {
+		return err
+	}
+
+	var columns []string
+	next, err := l.Peek()
+	if err != nil {
+		return err
+	}
+	if next.is("(") {
+		l.Next()
+		for {
+			name, err := parseName(l)
+			if err != nil {
+				return err
+			}
+			columns = append(columns, name)
+			sep, err := l.Next()
+			if err != nil {
+				return err
+			}
+			if sep.is(")") {
+				break
+			}
+			if !sep.is(",") {
+				return l.errorf(sep.line, "expected ',' or ')' in column list, found %q", sep.text)
+			}
+		}
+	}
+
+	kw, err := l.Next()
+	if err != nil {
+		return err
+	}
+	if !kw.is("VALUES") && !kw.is("VALUE") {
+		return l.errorf(kw.line, "expected VALUES, found %q", kw.text)
+	}
+
+	for {
+		open, err := expect(l, "(")
+		if err != nil {
+			return err
+		}
+		values, err := parseTuple(l)
+		if err != nil {
+			return err
+		}
+		if columns != nil && len(values) != len(columns) {
+			return l.errorf(open.line, "row has %d values, expected %d", len(values), len(columns))
+		}
+		if err := h.InsertRow(table, columns, values, open.line); err != nil {
+			return err
+		}
+
+		sep, err := l.Next()
+		if err != nil {
+			return err
+		}
+		if sep.is(",") {
+			continue
+		}
+		if sep.is(";") || sep.kind == tokEOF {
+			return h.EndInsert(table, sep.line)
+		}
+		if sep.is("ON") {
+			return l.errorf(sep.line, "ON DUPLICATE KEY UPDATE is not supported")
+		}
+		return l.errorf(sep.line, "expected ',' or ';' after row, found %q", sep.text)
+	}
+}
+
+// parseTuple reads the values of one row after its opening parenthesis.
+func parseTuple(l *lexer) ([]interface{}, error) {
+	var values []interface{}
+	for {
+		v, err := parseValue(l)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+
+		sep, err := l.Next()
+		if err != nil {
+			return nil, err
+		}
+		if sep.is(")") {
+			return values, nil
+		}
+		if !sep.is(",") {
+			return nil, l.errorf(sep.line, "expected ',' or ')' in row, found %q", sep.text)
+		}
+	}
+}
+
+// parseValue reads a literal value. Numbers are returned as strings and
+// converted by DuckDB according to the column type.
+func parseValue(l *lexer) (interface{}, error) {
+	t, err := l.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	sign := ""
+	if t.is("-") || t.is("+") {
+		sign = t.text
+		if t, err = l.Next(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch t.kind {
+	case tokString:
+		if sign == "" {
+			return t.text, nil
+		}
+	case tokWord:
+		if t.is("NULL") && sign == "" {
+			return nil, nil
+		}
+		if strings.HasPrefix(t.text, "_") {
+			// Character set introducer such as _utf8mb4'text'
+			return parseValue(l)
+		}
+		if t.text[0] == '.' || unicode.IsDigit(rune(t.text[0])) {
+			return sign + t.text, nil
+		}
+	}
+	return nil, l.errorf(t.line, "unsupported value %q", sign+t.text)
+}
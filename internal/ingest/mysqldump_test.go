@@ -0,0 +1,103 @@
+package ingest
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+const mysqlDump = `-- MySQL dump 10.13  Distrib 8.0.36
+/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;
+/*!40101 SET NAMES utf8mb4 */;
+SET FOREIGN_KEY_CHECKS = 0;
+# hash comment
+
+DROP TABLE IF EXISTS ` + "`wilayah`" + `;
+CREATE TABLE IF NOT EXISTS ` + "`wilayah`" + ` (
+  ` + "`kode`" + ` varchar(13) NOT NULL,
+  ` + "`nama`" + ` varchar(100) DEFAULT NULL COMMENT 'nama; wilayah',
+  ` + "`luas`" + ` decimal(10,2) DEFAULT NULL,
+  PRIMARY KEY (` + "`kode`" + `),
+  KEY ` + "`wilayah_nama`" + ` (` + "`nama`" + `)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+CREATE INDEX wilayah_kode_IDX USING BTREE ON wilayah (kode);
+
+LOCK TABLES ` + "`wilayah`" + ` WRITE;
+INSERT INTO ` + "`wilayah`" + ` (` + "`kode`" + `, ` + "`nama`" + `, ` + "`luas`" + `) VALUES
+('11', 'Aceh', 57956.00),
+('11.01', 'Kab. Aceh Selatan', NULL),
+('11.01.01', 'Bakongan', -1.5),
+('11.01.01.2001', 'Keude Bakongan; \'Lama\'', .5),
+('11.01.01.2002', 'Ujong ''Mangki''', 0);
+INSERT IGNORE INTO wilayah VALUES ('11.01.01.2003', _utf8mb4'Ujong\\Padang', 1);
+UNLOCK TABLES;
+`
+
+func TestLoadSQL(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if err := LoadSQL(db, strings.NewReader(mysqlDump), "dump.sql", 2); err != nil {
+		t.Fatalf("LoadSQL returned error: %v", err)
+	}
+
+	rows, err := db.Query("SELECT kode, nama, luas FROM wilayah ORDER BY kode")
+	if err != nil {
+		t.Fatalf("failed to query wilayah: %v", err)
+	}
+	defer rows.Close()
+
+	var got []string
+	for rows.Next() {
+		var kode string
+		var nama sql.NullString
+		var luas sql.NullFloat64
+		if err := rows.Scan(&kode, &nama, &luas); err != nil {
+			t.Fatalf("failed to scan row: %v", err)
+		}
+		got = append(got, kode+"|"+nama.String)
+		if kode == "11.01.01" && luas.Float64 != -1.5 {
+			t.Errorf("luas of %s = %v, want -1.5", kode, luas.Float64)
+		}
+	}
+
+	want := []string{
+		"11|Aceh",
+		"11.01|Kab. Aceh Selatan",
+		"11.01.01|Bakongan",
+		"11.01.01.2001|Keude Bakongan; 'Lama'",
+		"11.01.01.2002|Ujong 'Mangki'",
+		`11.01.01.2003|Ujong\Padang`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected rows:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLoadSQLErrorLine(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	dump := "CREATE TABLE t (a varchar(2));\n" +
+		"INSERT INTO t VALUES\n" +
+		"('x'),\n" +
+		"(NOW());\n"
+
+	err = LoadSQL(db, strings.NewReader(dump), "bad.sql", 0)
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("expected SyntaxError, got %v", err)
+	}
+	if syntaxErr.Line != 4 {
+		t.Errorf("error line = %d, want 4 (%v)", syntaxErr.Line, err)
+	}
+}
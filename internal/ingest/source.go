@@ -0,0 +1,357 @@
+package ingest
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Mapping names the columns (or JSON keys) of a tabular source file.
+type Mapping struct {
+	// Code is the column holding the Kemendagri code, with or without dots.
+	Code string `json:"code"`
+	// Name is the column holding the region name. Leave empty for postal-code-only files.
+	Name string `json:"name"`
+	// PostalCode is the column holding the postal code. Optional.
+	PostalCode string `json:"postal_code"`
+	// Sheet is the XLSX worksheet to read. Defaults to the first sheet.
+	Sheet string `json:"sheet,omitempty"`
+	// Delimiter is the CSV field separator. Defaults to a comma.
+	Delimiter string `json:"delimiter,omitempty"`
+}
+
+// DefaultMapping returns the mapping matching the upstream column names.
+func DefaultMapping() Mapping {
+	return Mapping{Code: "kode", Name: "nama", PostalCode: "kodepos"}
+}
+
+// LoadMapping reads a JSON mapping file. Fields missing from the file keep their defaults.
+func LoadMapping(path string) (Mapping, error) {
+	m := DefaultMapping()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, fmt.Errorf("failed to read mapping file: %w", err)
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("failed to parse mapping file %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// recordReader yields the records of a tabular file keyed by column name.
+// It returns io.EOF after the last record.
+type recordReader interface {
+	Next() (map[string]string, error)
+}
+
+// LoadTabularFile loads a CSV, JSON, NDJSON or XLSX file into the raw wilayah
+// and wilayah_kodepos tables, creating them when they do not exist yet, so the
+// rest of the pipeline is the same as for the SQL dumps. The format is chosen
+// from the file extension.
+func LoadTabularFile(db *sql.DB, path string, m Mapping) error {
+	if m.Code == "" {
+		return errors.New("mapping must name the code column")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open source file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var records recordReader
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv", ".tsv":
+		records, err = newCSVReader(f, ext, m)
+	case ".json", ".ndjson", ".jsonl":
+		records, err = newJSONReader(f)
+	case ".xlsx":
+		records, err = newXLSXReader(f, m)
+	default:
+		return fmt.Errorf("unsupported source format %q", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if c, ok := records.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{
+		"CREATE TABLE IF NOT EXISTS wilayah (kode VARCHAR, nama VARCHAR)",
+		"CREATE TABLE IF NOT EXISTS wilayah_kodepos (kode VARCHAR, kodepos VARCHAR)",
+	} {
+		if _, err := tx.Exec(table); err != nil {
+			return fmt.Errorf("failed to create raw tables: %w", err)
+		}
+	}
+
+	regions := &dumpLoader{tx: tx, file: path, batchSize: DefaultBatchSize}
+	postal := &dumpLoader{tx: tx, file: path, batchSize: DefaultBatchSize}
+	regionColumns := []string{"kode", "nama"}
+	postalColumns := []string{"kode", "kodepos"}
+
+	for n := 1; ; n++ {
+		record, err := records.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%s: record %d: %w", path, n, err)
+		}
+
+		code, ok := record[m.Code]
+		if !ok {
+			return fmt.Errorf("%s: record %d: column %q not found", path, n, m.Code)
+		}
+		code = NormalizeCode(code)
+		if code == "" {
+			continue
+		}
+
+		if m.Name != "" {
+			name, ok := record[m.Name]
+			if !ok {
+				return fmt.Errorf("%s: record %d: column %q not found", path, n, m.Name)
+			}
+			if err := regions.InsertRow(RawRegionsTable, regionColumns, []interface{}{code, name}, n); err != nil {
+				return err
+			}
+		}
+		if m.PostalCode != "" {
+			if postalCode := strings.TrimSpace(record[m.PostalCode]); postalCode != "" {
+				if err := postal.InsertRow(RawPostalCodesTable, postalColumns, []interface{}{code, postalCode}, n); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := regions.flush(); err != nil {
+		return err
+	}
+	if err := postal.flush(); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit %s: %w", path, err)
+	}
+	return nil
+}
+
+// NormalizeCode trims a Kemendagri code and adds the dots to undotted codes,
+// so "1101012001" becomes "11.01.01.2001".
+func NormalizeCode(code string) string {
+	code = strings.TrimSpace(code)
+	if strings.Contains(code, ".") {
+		return code
+	}
+	if _, err := strconv.ParseUint(code, 10, 64); err != nil {
+		return code
+	}
+
+	// Segment widths of province, city, district and village codes
+	widths := []int{2, 2, 2, 4}
+	var parts []string
+	rest := code
+	for _, w := range widths {
+		if len(rest) == 0 {
+			break
+		}
+		if len(rest) < w {
+			return code
+		}
+		parts = append(parts, rest[:w])
+		rest = rest[w:]
+	}
+	if rest != "" {
+		return code
+	}
+	return strings.Join(parts, ".")
+}
+
+// csvReader reads CSV records using the first row as the header.
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+func newCSVReader(r io.Reader, ext string, m Mapping) (*csvReader, error) {
+	cr := csv.NewReader(bufio.NewReader(r))
+	cr.ReuseRecord = true
+	cr.FieldsPerRecord = -1
+	switch {
+	case m.Delimiter != "":
+		cr.Comma = []rune(m.Delimiter)[0]
+	case ext == ".tsv":
+		cr.Comma = '\t'
+	}
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	return &csvReader{r: cr, header: trimHeader(header)}, nil
+}
+
+func (c *csvReader) Next() (map[string]string, error) {
+	fields, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	return zipRecord(c.header, fields), nil
+}
+
+// jsonReader reads objects from a JSON array or from newline-delimited JSON.
+type jsonReader struct {
+	dec *json.Decoder
+}
+
+func newJSONReader(r io.Reader) (*jsonReader, error) {
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); string(bom) == "\ufeff" {
+		br.Discard(3)
+	}
+
+	j := &jsonReader{dec: json.NewDecoder(br)}
+	j.dec.UseNumber()
+
+	// Skip the opening bracket of an array; NDJSON is a plain stream of objects
+	for {
+		b, err := br.Peek(1)
+		if err != nil || !unicode.IsSpace(rune(b[0])) {
+			if err == nil && b[0] == '[' {
+				if _, err := j.dec.Token(); err != nil {
+					return nil, err
+				}
+			}
+			return j, nil
+		}
+		br.ReadByte()
+	}
+}
+
+func (j *jsonReader) Next() (map[string]string, error) {
+	if !j.dec.More() {
+		return nil, io.EOF
+	}
+	var object map[string]interface{}
+	if err := j.dec.Decode(&object); err != nil {
+		return nil, err
+	}
+
+	record := make(map[string]string, len(object))
+	for k, v := range object {
+		switch v := v.(type) {
+		case nil:
+			record[k] = ""
+		case string:
+			record[k] = v
+		case json.Number:
+			record[k] = v.String()
+		default:
+			record[k] = fmt.Sprint(v)
+		}
+	}
+	return record, nil
+}
+
+// xlsxReader streams rows from one worksheet using the first row as the header.
+type xlsxReader struct {
+	file   *excelize.File
+	rows   *excelize.Rows
+	header []string
+}
+
+func newXLSXReader(r io.Reader, m Mapping) (*xlsxReader, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := m.Sheet
+	if sheet == "" {
+		sheet = file.GetSheetName(0)
+	}
+	rows, err := file.Rows(sheet)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open sheet %q: %w", sheet, err)
+	}
+
+	x := &xlsxReader{file: file, rows: rows}
+	header, err := x.row()
+	if err != nil {
+		x.Close()
+		if err == io.EOF {
+			return nil, fmt.Errorf("sheet %q is empty", sheet)
+		}
+		return nil, err
+	}
+	x.header = trimHeader(header)
+	return x, nil
+}
+
+// row returns the cells of the next worksheet row.
+func (x *xlsxReader) row() ([]string, error) {
+	if !x.rows.Next() {
+		if err := x.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return x.rows.Columns()
+}
+
+func (x *xlsxReader) Next() (map[string]string, error) {
+	cells, err := x.row()
+	if err != nil {
+		return nil, err
+	}
+	return zipRecord(x.header, cells), nil
+}
+
+// Close releases the worksheet iterator and the temporary files of the workbook.
+func (x *xlsxReader) Close() error {
+	x.rows.Close()
+	return x.file.Close()
+}
+
+// trimHeader removes surrounding whitespace and a UTF-8 byte order mark from header names.
+func trimHeader(header []string) []string {
+	trimmed := make([]string, len(header))
+	for i, h := range header {
+		trimmed[i] = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+	}
+	return trimmed
+}
+
+// zipRecord pairs header names with field values. Missing trailing fields are empty.
+func zipRecord(header, fields []string) map[string]string {
+	record := make(map[string]string, len(header))
+	for i, h := range header {
+		if i < len(fields) {
+			record[h] = fields[i]
+		} else {
+			record[h] = ""
+		}
+	}
+	return record
+}
@@ -0,0 +1,116 @@
+package ingest
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/marcboeker/go-duckdb"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestNormalizeCode(t *testing.T) {
+	tests := map[string]string{
+		"32":            "32",
+		"3273":          "32.73",
+		"327301":        "32.73.01",
+		"3273011001":    "32.73.01.1001",
+		" 32.73.01 ":    "32.73.01",
+		"32.73.01.1001": "32.73.01.1001",
+		"327":           "327",
+		"ABC":           "ABC",
+	}
+	for in, want := range tests {
+		if got := NormalizeCode(in); got != want {
+			t.Errorf("NormalizeCode(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// rawRows returns the raw tables as "kode|nama|kodepos" lines ordered by code.
+func rawRows(t *testing.T, db *sql.DB) string {
+	t.Helper()
+	rows, err := db.Query(`
+		SELECT w.kode, w.nama, COALESCE(k.kodepos, '')
+		FROM wilayah AS w LEFT JOIN wilayah_kodepos AS k ON k.kode = w.kode
+		ORDER BY w.kode`)
+	if err != nil {
+		t.Fatalf("failed to query raw tables: %v", err)
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var kode, nama, kodepos string
+		if err := rows.Scan(&kode, &nama, &kodepos); err != nil {
+			t.Fatalf("failed to scan row: %v", err)
+		}
+		lines = append(lines, kode+"|"+nama+"|"+kodepos)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestLoadTabularFile(t *testing.T) {
+	const want = "32|Jawa Barat|\n32.73|Kota Bandung|\n32.73.01|Sukasari|\n32.73.01.1001|Sarijadi|40151"
+	dir := t.TempDir()
+
+	mapping := Mapping{Code: "kode_wilayah", Name: "nama", PostalCode: "kode_pos"}
+	files := map[string]string{
+		"regions.csv": "kode_wilayah;nama;kode_pos\n32;Jawa Barat;\n3273;Kota Bandung;\n327301;Sukasari;\n3273011001;Sarijadi;40151\n",
+		"regions.json": `[{"kode_wilayah": "32", "nama": "Jawa Barat"}, {"kode_wilayah": "32.73", "nama": "Kota Bandung"},
+			{"kode_wilayah": "32.73.01", "nama": "Sukasari", "kode_pos": null},
+			{"kode_wilayah": 3273011001, "nama": "Sarijadi", "kode_pos": 40151}]`,
+		"regions.ndjson": `{"kode_wilayah": "32", "nama": "Jawa Barat"}
+{"kode_wilayah": "32.73", "nama": "Kota Bandung"}
+{"kode_wilayah": "32.73.01", "nama": "Sukasari"}
+{"kode_wilayah": "32.73.01.1001", "nama": "Sarijadi", "kode_pos": "40151"}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	xlsx := excelize.NewFile()
+	sheetRows := [][]interface{}{
+		{"kode_wilayah", "nama", "kode_pos"},
+		{"32", "Jawa Barat"},
+		{"32.73", "Kota Bandung"},
+		{"32.73.01", "Sukasari"},
+		{"32.73.01.1001", "Sarijadi", "40151"},
+	}
+	for i, row := range sheetRows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := xlsx.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatalf("failed to write xlsx row: %v", err)
+		}
+	}
+	if err := xlsx.SaveAs(filepath.Join(dir, "regions.xlsx")); err != nil {
+		t.Fatalf("failed to save xlsx: %v", err)
+	}
+	files["regions.xlsx"] = ""
+
+	for name := range files {
+		t.Run(name, func(t *testing.T) {
+			db, err := sql.Open("duckdb", "")
+			if err != nil {
+				t.Fatalf("failed to open database: %v", err)
+			}
+			defer db.Close()
+
+			m := mapping
+			if strings.HasSuffix(name, ".csv") {
+				m.Delimiter = ";"
+			}
+			if err := LoadTabularFile(db, filepath.Join(dir, name), m); err != nil {
+				t.Fatalf("LoadTabularFile returned error: %v", err)
+			}
+			if got := rawRows(t, db); got != want {
+				t.Errorf("unexpected rows:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package ingest
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/schema"
+)
+
+// Raw table names created by the optional upstream statistics dumps.
+const (
+	RawStatsTable      = "wilayah_level_1_2"
+	RawBoundariesTable = "wilayah_boundaries"
+)
+
+// statsColumns maps each region stats column to its type and the upstream
+// column it is read from.
+var statsColumns = []struct {
+	name   string
+	typ    string
+	source string
+}{
+	{"capital", "VARCHAR", "ibukota"},
+	{"latitude", "DOUBLE", "lat"},
+	{"longitude", "DOUBLE", "lng"},
+	{"elevation", "DOUBLE", "elv"},
+	{"timezone", "INTEGER", "tz"},
+	{"area_km2", "DOUBLE", "luas"},
+	{"population", "BIGINT", "penduduk"},
+	{"boundary", "VARCHAR", "path"},
+}
+
+// LoadSQLFiles loads a single SQL dump, or every .sql file in a directory in
+// name order. Upstream splits the boundary data into one dump per region, so
+// the rows of every file in a directory are appended to the same tables.
+func LoadSQLFiles(db *sql.DB, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return LoadSQLFile(db, path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", path, err)
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".sql") {
+			files = append(files, filepath.Join(path, e.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	for i, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return fmt.Errorf("failed to read SQL file %s: %w", file, err)
+		}
+		err = loadSQL(db, f, file, DefaultBatchSize, i > 0)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BuildRegionStats creates the region stats table from the raw
+// wilayah_level_1_2 and wilayah_boundaries tables, whichever were loaded.
+// Boundary paths and coordinates from wilayah_boundaries take precedence.
+// Without either table any stale region stats table is dropped.
+func BuildRegionStats(db *sql.DB) error {
+	var sources []string
+	available := make(map[string]map[string]bool)
+	for _, table := range []string{RawBoundariesTable, RawStatsTable} {
+		columns, err := tableColumns(db, table)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		available[table] = make(map[string]bool)
+		for _, c := range columns {
+			available[table][strings.ToLower(c)] = true
+		}
+		if !available[table]["kode"] {
+			return fmt.Errorf("table %s has no kode column", table)
+		}
+		sources = append(sources, table)
+	}
+	if len(sources) == 0 {
+		if _, err := db.Exec("DROP TABLE IF EXISTS " + schema.RegionStatsTable); err != nil {
+			return fmt.Errorf("failed to drop %s table: %w", schema.RegionStatsTable, err)
+		}
+		return nil
+	}
+
+	// One row per province or city code found in any source table
+	var codes []string
+	for _, table := range sources {
+		codes = append(codes, "SELECT TRIM(kode) AS code FROM "+table)
+	}
+	selected := []string{"c.code"}
+	joins := ""
+	for _, table := range sources {
+		// Keep a single row per code in case a dump repeats one
+		joins += " LEFT JOIN (SELECT * FROM " + table + " QUALIFY row_number() OVER (PARTITION BY TRIM(kode)) = 1) AS " +
+			table + " ON TRIM(" + table + ".kode) = c.code"
+	}
+	for _, col := range statsColumns {
+		var values []string
+		for _, table := range sources {
+			if available[table][col.source] {
+				values = append(values, "TRY_CAST("+table+"."+col.source+" AS "+col.typ+")")
+			}
+		}
+		values = append(values, "CAST(NULL AS "+col.typ+")")
+		selected = append(selected, "COALESCE("+strings.Join(values, ", ")+") AS "+col.name)
+	}
+
+	query := "CREATE OR REPLACE TABLE " + schema.RegionStatsTable + " AS SELECT " + strings.Join(selected, ", ") +
+		" FROM (SELECT DISTINCT code FROM (" + strings.Join(codes, " UNION ALL ") + ") WHERE LENGTH(code) IN (2, 5)) AS c" +
+		joins + " ORDER BY c.code"
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to build region stats: %w", err)
+	}
+	return nil
+}
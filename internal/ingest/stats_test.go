@@ -0,0 +1,72 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+func TestBuildRegionStats(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	dir := t.TempDir()
+	statsPath := writeDump(t, dir, "wilayah_level_1_2.sql", RawStatsTable,
+		"kode varchar(13), nama varchar(100), ibukota varchar(100), lat double, lng double, luas double, penduduk double, path longtext",
+		"('32','JAWA BARAT','Bandung',-6.90,107.57,35377.76,49935858,'[[[-6.1,106.9]]]'),"+
+			"('32.73','KOTA BANDUNG','Bandung',-6.91,107.60,167.31,2452943,NULL),"+
+			"('32.73.01','SUKASARI',NULL,NULL,NULL,NULL,NULL,NULL)")
+
+	// Boundaries split across a directory of dumps are appended together
+	boundaries := filepath.Join(dir, "boundaries")
+	if err := os.Mkdir(boundaries, 0o755); err != nil {
+		t.Fatalf("failed to create boundaries directory: %v", err)
+	}
+	const boundaryColumns = "kode varchar(13), lat double, lng double, path longtext"
+	writeDump(t, boundaries, "31.sql", RawBoundariesTable, boundaryColumns, "('31',-6.2,106.8,'[[[-6.2,106.8]]]')")
+	writeDump(t, boundaries, "32.sql", RawBoundariesTable, boundaryColumns, "('32.73',-6.92,107.61,'[[[-6.92,107.61]]]')")
+
+	for _, path := range []string{statsPath, boundaries} {
+		if err := LoadSQLFiles(db, path); err != nil {
+			t.Fatalf("LoadSQLFiles(%s) returned error: %v", path, err)
+		}
+	}
+	if err := BuildRegionStats(db); err != nil {
+		t.Fatalf("BuildRegionStats returned error: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + service.RegionStatsTable).Scan(&count); err != nil {
+		t.Fatalf("failed to count region stats: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("region stats has %d rows, want 3 (districts are skipped)", count)
+	}
+
+	svc := service.New(db)
+	stats, err := svc.Stats(context.Background(), "32.73", true)
+	if err != nil {
+		t.Fatalf("Stats returned error: %v", err)
+	}
+	if stats.Latitude == nil || *stats.Latitude != -6.92 {
+		t.Errorf("latitude = %v, want the boundary value -6.92", stats.Latitude)
+	}
+	if stats.Population == nil || *stats.Population != 2452943 {
+		t.Errorf("population = %v, want 2452943", stats.Population)
+	}
+	if string(stats.Boundary) != "[[[-6.92,107.61]]]" {
+		t.Errorf("boundary = %s", stats.Boundary)
+	}
+
+	if _, err := svc.Stats(context.Background(), "32.73.01", false); !service.IsError(err, service.ErrCodeNotFound) {
+		t.Errorf("expected not found error for a district, got %v", err)
+	}
+}
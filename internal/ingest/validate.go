@@ -0,0 +1,290 @@
+package ingest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Validation check names.
+const (
+	CheckOrphanCodes               = "orphan_codes"
+	CheckVillagesWithoutPostalCode = "villages_without_postal_code"
+	CheckDuplicateCodes            = "duplicate_codes"
+	CheckPostalCodesWithoutVillage = "postal_codes_without_village"
+	CheckUntrimmedNames            = "untrimmed_names"
+	CheckOddCasing                 = "odd_casing"
+	CheckDuplicateNames            = "duplicate_names"
+)
+
+// NoThreshold marks a check that is reported but never fails validation.
+const NoThreshold = -1
+
+// DefaultSampleLimit is the number of issues kept per check when no limit is given.
+const DefaultSampleLimit = 10
+
+// DefaultThresholds returns the maximum number of issues allowed per check
+// before validation fails. Checks that are not listed never fail.
+func DefaultThresholds() map[string]int {
+	return map[string]int{
+		CheckOrphanCodes:    0,
+		CheckDuplicateCodes: 0,
+	}
+}
+
+// parentCode is a SQL expression returning the parent code of the kode column.
+const parentCode = `CASE LENGTH(kode)
+	WHEN 13 THEN SUBSTRING(kode FROM 1 FOR 8)
+	WHEN 8 THEN SUBSTRING(kode FROM 1 FOR 5)
+	WHEN 5 THEN SUBSTRING(kode FROM 1 FOR 2)
+END`
+
+// check is a single data-quality query over the raw tables.
+// Each query returns one row per issue with the columns (code, name, detail).
+type check struct {
+	name        string
+	description string
+	query       string
+}
+
+var checks = []check{
+	{
+		name:        CheckOrphanCodes,
+		description: "Codes whose province, city or district is missing (villages are dropped from regions)",
+		query: `
+			SELECT w.kode, w.nama, 'missing province ' || SUBSTRING(w.kode FROM 1 FOR 2)
+			FROM wilayah AS w
+			WHERE LENGTH(w.kode) > 2
+				AND NOT EXISTS (SELECT 1 FROM wilayah AS p WHERE p.kode = SUBSTRING(w.kode FROM 1 FOR 2))
+			UNION ALL
+			SELECT w.kode, w.nama, 'missing city ' || SUBSTRING(w.kode FROM 1 FOR 5)
+			FROM wilayah AS w
+			WHERE LENGTH(w.kode) > 5
+				AND NOT EXISTS (SELECT 1 FROM wilayah AS p WHERE p.kode = SUBSTRING(w.kode FROM 1 FOR 5))
+			UNION ALL
+			SELECT w.kode, w.nama, 'missing district ' || SUBSTRING(w.kode FROM 1 FOR 8)
+			FROM wilayah AS w
+			WHERE LENGTH(w.kode) > 8
+				AND NOT EXISTS (SELECT 1 FROM wilayah AS p WHERE p.kode = SUBSTRING(w.kode FROM 1 FOR 8))
+			ORDER BY 1, 3
+		`,
+	},
+	{
+		name:        CheckVillagesWithoutPostalCode,
+		description: "Villages without a postal code",
+		query: `
+			SELECT w.kode, w.nama, ''
+			FROM wilayah AS w
+			WHERE LENGTH(w.kode) = 13
+				AND NOT EXISTS (SELECT 1 FROM wilayah_kodepos AS k WHERE k.kode = w.kode)
+			ORDER BY 1
+		`,
+	},
+	{
+		name:        CheckDuplicateCodes,
+		description: "Codes that appear more than once in the same table",
+		query: `
+			SELECT kode, MIN(nama), 'wilayah: ' || CAST(COUNT(*) AS VARCHAR) || ' rows'
+			FROM wilayah
+			GROUP BY kode
+			HAVING COUNT(*) > 1
+			UNION ALL
+			SELECT kode, '', 'wilayah_kodepos: postal codes ' || string_agg(kodepos, ', ' ORDER BY kodepos)
+			FROM wilayah_kodepos
+			GROUP BY kode
+			HAVING COUNT(*) > 1
+			ORDER BY 1, 3
+		`,
+	},
+	{
+		name:        CheckPostalCodesWithoutVillage,
+		description: "Postal codes pointing to a village code that does not exist",
+		query: `
+			SELECT k.kode, '', 'postal code ' || k.kodepos
+			FROM wilayah_kodepos AS k
+			WHERE NOT EXISTS (SELECT 1 FROM wilayah AS w WHERE w.kode = k.kode AND LENGTH(w.kode) = 13)
+			ORDER BY 1, 3
+		`,
+	},
+	{
+		name:        CheckUntrimmedNames,
+		description: "Names with leading, trailing, repeated or control whitespace",
+		query: `
+			SELECT kode, nama, 'stray whitespace'
+			FROM wilayah
+			WHERE nama <> TRIM(nama)
+				OR nama LIKE '%  %'
+				OR regexp_matches(nama, '[\t\r\n]')
+			ORDER BY 1
+		`,
+	},
+	{
+		name:        CheckOddCasing,
+		description: "Names written entirely in upper or lower case",
+		query: `
+			SELECT kode, nama, CASE WHEN nama = UPPER(nama) THEN 'all upper case' ELSE 'all lower case' END
+			FROM wilayah
+			WHERE regexp_matches(nama, '[A-Za-z]{2}')
+				AND (nama = UPPER(nama) OR nama = LOWER(nama))
+			ORDER BY 1
+		`,
+	},
+	{
+		name:        CheckDuplicateNames,
+		description: "Names that appear more than once under the same parent",
+		query: `
+			SELECT parent, MIN(nama), CAST(COUNT(*) AS VARCHAR) || ' codes: ' || string_agg(kode, ', ' ORDER BY kode)
+			FROM (SELECT kode, nama, COALESCE(` + parentCode + `, '') AS parent FROM wilayah)
+			GROUP BY parent, LOWER(TRIM(nama))
+			HAVING COUNT(*) > 1
+			ORDER BY 1, 2
+		`,
+	},
+}
+
+// Issue is a single data-quality finding.
+type Issue struct {
+	Code   string `json:"code"`
+	Name   string `json:"name,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// CheckResult holds the outcome of one data-quality check.
+type CheckResult struct {
+	Check       string  `json:"check"`
+	Description string  `json:"description"`
+	Count       int     `json:"count"`
+	Threshold   int     `json:"threshold"`
+	Failed      bool    `json:"failed"`
+	Samples     []Issue `json:"samples,omitempty"`
+}
+
+// Report is the result of validating the raw upstream tables.
+type Report struct {
+	Passed bool          `json:"passed"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// ValidateOptions configures Validate.
+type ValidateOptions struct {
+	// Thresholds is the maximum number of issues allowed per check.
+	// Checks that are missing or set to NoThreshold never fail.
+	Thresholds map[string]int
+	// SampleLimit is the number of issues kept per check. Zero means DefaultSampleLimit.
+	SampleLimit int
+}
+
+// CheckNames returns the names of all validation checks in report order.
+func CheckNames() []string {
+	names := make([]string, len(checks))
+	for i, c := range checks {
+		names[i] = c.name
+	}
+	return names
+}
+
+// Validate runs every data-quality check against the raw wilayah and
+// wilayah_kodepos tables. It must be called before DropRawTables.
+func Validate(db *sql.DB, opts ValidateOptions) (*Report, error) {
+	limit := opts.SampleLimit
+	if limit <= 0 {
+		limit = DefaultSampleLimit
+	}
+
+	report := &Report{Passed: true}
+	for _, c := range checks {
+		threshold, ok := opts.Thresholds[c.name]
+		if !ok {
+			threshold = NoThreshold
+		}
+
+		result, err := runCheck(db, c, limit)
+		if err != nil {
+			return nil, err
+		}
+		result.Threshold = threshold
+		result.Failed = threshold != NoThreshold && result.Count > threshold
+		if result.Failed {
+			report.Passed = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report, nil
+}
+
+// runCheck executes a check query, counting every issue and keeping up to limit samples.
+func runCheck(db *sql.DB, c check, limit int) (CheckResult, error) {
+	result := CheckResult{Check: c.name, Description: c.description}
+
+	rows, err := db.Query(c.query)
+	if err != nil {
+		return result, fmt.Errorf("failed to run %s check: %w", c.name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code, name, detail sql.NullString
+		if err := rows.Scan(&code, &name, &detail); err != nil {
+			return result, fmt.Errorf("failed to scan %s check: %w", c.name, err)
+		}
+		result.Count++
+		if len(result.Samples) < limit {
+			result.Samples = append(result.Samples, Issue{Code: code.String, Name: name.String, Detail: detail.String})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("failed to iterate %s check: %w", c.name, err)
+	}
+	return result, nil
+}
+
+// Failures returns the names of the checks that exceeded their threshold.
+func (r *Report) Failures() []string {
+	var names []string
+	for _, c := range r.Checks {
+		if c.Failed {
+			names = append(names, c.Check)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the report in a human-readable form.
+func (r *Report) WriteText(w io.Writer) error {
+	status := "PASSED"
+	if !r.Passed {
+		status = "FAILED"
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Data quality report: %s\n\n", status)
+	for _, c := range r.Checks {
+		mark := "ok"
+		if c.Failed {
+			mark = "FAIL"
+		}
+		limit := "no limit"
+		if c.Threshold != NoThreshold {
+			limit = fmt.Sprintf("max %d", c.Threshold)
+		}
+		fmt.Fprintf(tw, "[%s]\t%s\t%d\t(%s)\t%s\n", mark, c.Check, c.Count, limit, c.Description)
+		for _, issue := range c.Samples {
+			fmt.Fprintf(tw, "\t  %s\t%s\t%s\t\n", issue.Code, issue.Name, issue.Detail)
+		}
+		if more := c.Count - len(c.Samples); more > 0 {
+			fmt.Fprintf(tw, "\t  ... and %d more\t\t\t\n", more)
+		}
+	}
+	return tw.Flush()
+}
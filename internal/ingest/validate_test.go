@@ -0,0 +1,101 @@
+package ingest
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+// openRawDB returns an in-memory database holding raw wilayah tables with
+// one known issue for every validation check.
+func openRawDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE wilayah (kode VARCHAR, nama VARCHAR);
+		CREATE TABLE wilayah_kodepos (kode VARCHAR, kodepos VARCHAR);
+		INSERT INTO wilayah VALUES
+			('32', 'Jawa Barat'),
+			('32.73', 'Kota Bandung'),
+			('32.73.01', 'Sukasari'),
+			('32.73.01.1001', 'Sarijadi'),
+			('32.73.01.1002', ' Sukarasa'),
+			('32.73.01.1003', 'SARIJADI'),
+			('32.73.09.1001', 'Hilang'),
+			('32.73.02', 'Cidadap'),
+			('32.73.02', 'Cidadap');
+		INSERT INTO wilayah_kodepos VALUES
+			('32.73.01.1001', '40151'),
+			('32.73.01.1002', '40152'),
+			('32.73.01.1003', '40153'),
+			('32.73.01.1003', '40154'),
+			('32.73.01.9999', '40159');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create raw tables: %v", err)
+	}
+	return db
+}
+
+func TestValidate(t *testing.T) {
+	db := openRawDB(t)
+
+	report, err := Validate(db, ValidateOptions{Thresholds: DefaultThresholds()})
+	if err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+
+	want := map[string]int{
+		CheckOrphanCodes:               1, // 32.73.09.1001 has no district
+		CheckVillagesWithoutPostalCode: 1, // 32.73.09.1001
+		CheckDuplicateCodes:            2, // 32.73.02 in wilayah, 32.73.01.1003 in wilayah_kodepos
+		CheckPostalCodesWithoutVillage: 1, // 32.73.01.9999
+		CheckUntrimmedNames:            1, // ' Sukarasa'
+		CheckOddCasing:                 1, // 'SARIJADI'
+		CheckDuplicateNames:            2, // Sarijadi/SARIJADI and Cidadap/Cidadap
+	}
+	for _, c := range report.Checks {
+		if c.Count != want[c.Check] {
+			t.Errorf("%s: got %d issues, want %d (%+v)", c.Check, c.Count, want[c.Check], c.Samples)
+		}
+	}
+
+	if report.Passed {
+		t.Error("expected report to fail with default thresholds")
+	}
+	if got := strings.Join(report.Failures(), ","); got != "duplicate_codes,orphan_codes" {
+		t.Errorf("unexpected failures: %s", got)
+	}
+}
+
+func TestValidateThresholds(t *testing.T) {
+	db := openRawDB(t)
+
+	report, err := Validate(db, ValidateOptions{
+		Thresholds:  map[string]int{CheckDuplicateCodes: 2, CheckOddCasing: NoThreshold},
+		SampleLimit: 1,
+	})
+	if err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+	if !report.Passed {
+		t.Errorf("expected report to pass, failures: %v", report.Failures())
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText returned error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "... and 1 more") {
+		t.Errorf("expected truncated samples in text report:\n%s", buf.String())
+	}
+}
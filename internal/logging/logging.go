@@ -0,0 +1,181 @@
+// Package logging builds the structured logger of the API and writes one
+// access log record per request. Records logged with a request context carry
+// the ID of the request, so service logs can be matched to access logs.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// Supported log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// RequestIDKey is the attribute key of the request ID.
+const RequestIDKey = "request_id"
+
+// Redacted replaces the value of redacted attributes.
+const Redacted = "[REDACTED]"
+
+// redactedKeys are the attributes holding user-supplied search terms, which
+// are replaced by Redacted when query redaction is enabled.
+var redactedKeys = map[string]bool{
+	"query":      true,
+	"postalCode": true,
+}
+
+// Config selects the output of the logger.
+type Config struct {
+	// Format is FormatText or FormatJSON. An empty format means text.
+	Format string
+
+	// Level is the minimum level logged: debug, info, warn or error. An
+	// empty level means info.
+	Level string
+
+	// RedactQueries hides search terms and query strings from the logs.
+	RedactQueries bool
+}
+
+// New returns a logger writing to w as configured by cfg.
+func New(w io.Writer, cfg Config) (*slog.Logger, error) {
+	var level slog.Level
+	if cfg.Level != "" {
+		if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q, must be one of debug, info, warn or error", cfg.Level)
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	if cfg.RedactQueries {
+		opts.ReplaceAttr = redact
+	}
+
+	var handler slog.Handler
+	switch cfg.Format {
+	case "", FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q, must be text or json", cfg.Format)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+// redact replaces the values of redactedKeys.
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if redactedKeys[a.Key] {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+type requestIDKey struct{}
+
+type attrsKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithAttrs returns a copy of ctx carrying attrs, which are added to the
+// records logged with it after those already carried.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	carried, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return context.WithValue(ctx, attrsKey{}, append(carried[:len(carried):len(carried)], attrs...))
+}
+
+// contextHandler adds the request ID and the attributes carried by the
+// context to every record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String(RequestIDKey, id))
+	}
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// responseSize returns the size of the response body, or -1 when it is
+// streamed without a known length. Streamed bodies are only produced once
+// the middleware has returned, and reading them would buffer them here.
+func responseSize(resp *fasthttp.Response) int {
+	if resp.IsBodyStream() {
+		return resp.Header.ContentLength()
+	}
+	return len(resp.Body())
+}
+
+// Middleware writes an access log record for every request to logger, with
+// its method, path, status, latency and response size, left out for streamed
+// responses of unknown length. It must run after the
+// requestid middleware: the request ID it assigned is stored in the user
+// context of the request, so that handlers passing c.UserContext() on log it
+// too. Errors returned by later handlers are passed to the app's error
+// handler first so that the final status is logged.
+func Middleware(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		ctx := WithRequestID(c.UserContext(), c.GetRespHeader(fiber.HeaderXRequestID))
+		c.SetUserContext(ctx)
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("ip", c.IP()),
+			slog.String("user_agent", c.Get(fiber.HeaderUserAgent)),
+		}
+		if size := responseSize(c.Response()); size >= 0 {
+			attrs = append(attrs, slog.Int("bytes", size))
+		}
+		if query := c.Request().URI().QueryString(); len(query) > 0 {
+			attrs = append(attrs, slog.String("query", string(query)))
+		}
+		// Later handlers may have added attributes, such as the API key label
+		logger.LogAttrs(c.UserContext(), level, "Request completed", attrs...)
+		return nil
+	}
+}
@@ -0,0 +1,121 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{Format: FormatJSON, Level: "debug", RedactQueries: true})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	app := fiber.New()
+	app.Use(requestid.New())
+	app.Use(Middleware(logger))
+	app.Get("/v1/search/city", func(c *fiber.Ctx) error {
+		logger.InfoContext(c.UserContext(), "Search completed", "query", c.Query("q"))
+		return c.SendString("ok")
+	})
+
+	req := httptest.NewRequest("GET", "/v1/search/city?q=bandung", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "req-42")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if got := resp.Header.Get(fiber.HeaderXRequestID); got != "req-42" {
+		t.Errorf("expected the incoming request ID to be echoed, got %q", got)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a service record and an access record, got %d lines: %s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("record is not JSON: %s", line)
+		}
+		if record[RequestIDKey] != "req-42" {
+			t.Errorf("record %q has request ID %v", record["msg"], record[RequestIDKey])
+		}
+		if record["query"] != Redacted {
+			t.Errorf("record %q leaks the query: %v", record["msg"], record["query"])
+		}
+	}
+
+	var access map[string]interface{}
+	_ = json.Unmarshal([]byte(lines[1]), &access)
+	if access["msg"] != "Request completed" || access["status"] != float64(200) || access["path"] != "/v1/search/city" {
+		t.Errorf("unexpected access record: %v", access)
+	}
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{Level: "warn"})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	logger.Info("hidden", "query", "bandung")
+	logger.Warn("shown", "query", "bandung")
+	if out := buf.String(); strings.Contains(out, "hidden") || !strings.Contains(out, "query=bandung") {
+		t.Errorf("unexpected text output: %s", out)
+	}
+
+	if _, err := New(&buf, Config{Format: "xml"}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	if _, err := New(&buf, Config{Level: "verbose"}); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
+
+func TestMiddlewareStreaming(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{Format: FormatJSON, Level: "info"})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	// The body must only be produced after the access record is written,
+	// or the middleware buffered the stream
+	var loggedFirst bool
+	app := fiber.New()
+	app.Use(Middleware(logger))
+	app.Get("/v1/export", func(c *fiber.Ctx) error {
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			loggedFirst = strings.Contains(buf.String(), "Request completed")
+			_, _ = w.WriteString("streamed")
+		})
+		return nil
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/v1/export", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "streamed" || !loggedFirst {
+		t.Errorf("expected the body %q to be streamed after logging, got %q (logged first: %v)", "streamed", body, loggedFirst)
+	}
+
+	var access map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &access); err != nil {
+		t.Fatalf("record is not JSON: %s", buf.String())
+	}
+	if _, ok := access["bytes"]; ok {
+		t.Errorf("expected no size for a stream of unknown length, got %v", access["bytes"])
+	}
+}
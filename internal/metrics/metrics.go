@@ -0,0 +1,146 @@
+// Package metrics exports Prometheus metrics for the API: HTTP traffic per
+// route, database query durations per service method, search totals, cache
+// lookups, API key usage and connection pool statistics. Metrics are
+// registered with the default Prometheus registry, alongside the Go runtime
+// and process metrics.
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes every metric defined by this package.
+const Namespace = "wilayah"
+
+// UnmatchedRoute is the route label of requests that matched no route, so
+// that unknown paths do not create a series each.
+const UnmatchedRoute = "unmatched"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route pattern and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "DuckDB query duration by service method.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 12),
+	}, []string{"method"})
+
+	searches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "searches_total",
+		Help:      "Searches run by service method.",
+	}, []string{"method"})
+
+	zeroResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "search_zero_results_total",
+		Help:      "Searches without any match by service method.",
+	}, []string{"method"})
+
+	cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "cache_requests_total",
+		Help:      "Service cache lookups by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	authRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "auth_requests_total",
+		Help:      "Authenticated requests by API key label, scope and result (allowed, unauthorized or forbidden).",
+	}, []string{"key", "scope", "result"})
+)
+
+// RegisterDB exports the connection pool statistics of db, as reported by
+// db.Stats(), under the given database name.
+func RegisterDB(db *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format.
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.Handler())
+}
+
+// Middleware records the count and latency of every request, labelled with
+// the route pattern rather than the path so that parameters such as region
+// codes do not create a series each. Errors returned by later handlers are
+// passed to the app's error handler first so that the final status is recorded.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		own := c.Route()
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		// The route is still this middleware when no handler matched
+		route := c.Route().Path
+		if c.Route() == own {
+			route = UnmatchedRoute
+		}
+		status := strconv.Itoa(c.Response().StatusCode())
+
+		httpRequests.WithLabelValues(c.Method(), route, status).Inc()
+		httpDuration.WithLabelValues(c.Method(), route, status).Observe(time.Since(start).Seconds())
+		return nil
+	}
+}
+
+// ServiceObserver implements service.Observer by recording to the metrics
+// of this package.
+type ServiceObserver struct{}
+
+// ObserveQuery records the duration of a database query.
+func (ServiceObserver) ObserveQuery(method string, duration time.Duration) {
+	queryDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+// ObserveSearch counts a search and whether it had no match.
+func (ServiceObserver) ObserveSearch(method string, total int) {
+	searches.WithLabelValues(method).Inc()
+	if total == 0 {
+		zeroResults.WithLabelValues(method).Inc()
+	}
+}
+
+// ObserveCache counts a cache lookup as a hit or a miss.
+func (ServiceObserver) ObserveCache(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// AuthObserver implements auth.Observer by recording to the metrics of this
+// package. Keys are identified by their label, never by the secret.
+type AuthObserver struct{}
+
+// ObserveAuth counts an authentication by key label, scope and result.
+func (AuthObserver) ObserveAuth(label, scope, result string) {
+	authRequests.WithLabelValues(label, scope, result).Inc()
+}
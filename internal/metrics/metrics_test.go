@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/v1/regions/:code", func(c *fiber.Ctx) error {
+		return c.SendString(c.Params("code"))
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "down")
+	})
+	app.Get("/metrics", Handler())
+
+	for _, path := range []string{"/v1/regions/32", "/v1/regions/32.73", "/unknown", "/fail"} {
+		if _, err := app.Test(httptest.NewRequest("GET", path, nil)); err != nil {
+			t.Fatalf("request to %s failed: %v", path, err)
+		}
+	}
+
+	if n := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/v1/regions/:code", "200")); n != 2 {
+		t.Errorf("expected 2 requests for the route pattern, got %v", n)
+	}
+	if n := testutil.ToFloat64(httpRequests.WithLabelValues("GET", UnmatchedRoute, "404")); n != 1 {
+		t.Errorf("expected 1 unmatched request, got %v", n)
+	}
+	if n := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/fail", "503")); n != 1 {
+		t.Errorf("expected the status set by the error handler, got %v requests", n)
+	}
+
+	ServiceObserver{}.ObserveSearch("SearchByCity", 0)
+	ServiceObserver{}.ObserveSearch("SearchByCity", 3)
+	ServiceObserver{}.ObserveCache("region_stats", true)
+	if n := testutil.ToFloat64(zeroResults.WithLabelValues("SearchByCity")); n != 1 {
+		t.Errorf("expected 1 zero-result search, got %v", n)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/metrics", nil))
+	if err != nil {
+		t.Fatalf("metrics request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	for _, want := range []string{
+		`wilayah_http_request_duration_seconds_bucket{method="GET",route="/v1/regions/:code",status="200"`,
+		`wilayah_searches_total{method="SearchByCity"} 2`,
+		`wilayah_cache_requests_total{cache="region_stats",result="hit"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics output does not contain %s", want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Package ratelimit limits the request rate of each client with token
+// buckets. Limits are configured per route group, so that expensive routes
+// such as the export can be limited more strictly than searches. Clients are
+// identified by their authenticated API key and by IP address otherwise.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/auth"
+)
+
+// Route groups sharing a limit.
+const (
+	GroupSearch = "search"
+	GroupExport = "export"
+	GroupBatch  = "batch"
+)
+
+// Rate limit response headers, as described by the IETF RateLimit header
+// fields draft.
+const (
+	HeaderLimit     = "RateLimit-Limit"
+	HeaderRemaining = "RateLimit-Remaining"
+	HeaderReset     = "RateLimit-Reset"
+)
+
+// Limit is a token bucket limit: a client may send Burst requests at once,
+// and the bucket refills at Rate requests per second. The zero Limit does not
+// limit anything.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Unlimited reports whether l lets every request through.
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// limitPeriods maps the units accepted by ParseLimit to their duration.
+var limitPeriods = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// ParseLimit parses a limit written as "<requests>/<unit>", where the unit is
+// s, m or h, optionally followed by ":<burst>". Without a burst, the whole
+// quota of a period may be used at once: "600/m" refills 10 requests per
+// second with a burst of 600, while "600/m:20" allows bursts of 20. An empty
+// string or "0" means unlimited.
+func ParseLimit(s string) (Limit, error) {
+	if s == "" || s == "0" {
+		return Limit{}, nil
+	}
+	spec, burstText, hasBurst := strings.Cut(s, ":")
+	countText, unit, ok := strings.Cut(spec, "/")
+	period, known := limitPeriods[unit]
+	if !ok || !known {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected <requests>/<s|m|h>[:<burst>]", s)
+	}
+	count, err := strconv.Atoi(countText)
+	if err != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, the request count must be a positive integer", s)
+	}
+	burst := count
+	if hasBurst {
+		burst, err = strconv.Atoi(burstText)
+		if err != nil || burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit %q, the burst must be a positive integer", s)
+		}
+	}
+	return Limit{Rate: float64(count) / period.Seconds(), Burst: burst}, nil
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	// Allowed reports whether a token was available.
+	Allowed bool
+
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+
+	// RetryAfter is the time until a token is available, when Allowed is false.
+	RetryAfter time.Duration
+
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+}
+
+// Store keeps the token buckets of the clients. Implementations must be safe
+// for concurrent use; MemoryStore keeps them in process, and a shared store
+// lets several replicas enforce the same limits.
+type Store interface {
+	// Take takes a token from the bucket of key, which refills according to
+	// limit, and reports whether one was available.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// ClientKey identifies clients by the label of the API key that
+// authenticated the request, and by IP address otherwise. Keys that were not
+// verified are ignored, or clients could get a new bucket with every request
+// by sending a new key. The limiter must therefore run after the auth
+// middleware.
+func ClientKey(c *fiber.Ctx) string {
+	if label := auth.Label(c.UserContext()); label != "" {
+		return "key:" + label
+	}
+	return "ip:" + c.IP()
+}
+
+// Limiter applies the limits of each route group to the requests of every
+// client.
+type Limiter struct {
+	store  Store
+	limits map[string]Limit
+	key    func(c *fiber.Ctx) string
+}
+
+// New returns a Limiter keeping its buckets in store and applying limits by
+// route group. Groups without a limit are not limited. Clients are identified
+// with ClientKey.
+func New(store Store, limits map[string]Limit) *Limiter {
+	return &Limiter{
+		store:  store,
+		limits: limits,
+		key:    ClientKey,
+	}
+}
+
+// Take takes a token from the bucket of client for group, as identified by
+// ClientKey or by the caller for other transports. Groups without a limit
+// always allow the request.
+func (l *Limiter) Take(ctx context.Context, group, client string) (Result, error) {
+	limit := l.limits[group]
+	if limit.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+	return l.store.Take(ctx, group+":"+client, limit)
+}
+
+// Middleware limits the requests to the routes of group. Every limited
+// response carries the RateLimit-* headers; once the bucket of a client is
+// empty, requests fail with 429 Too Many Requests and a Retry-After header.
+// Requests are let through when the store fails.
+func (l *Limiter) Middleware(group string) fiber.Handler {
+	limit := l.limits[group]
+	if limit.Unlimited() {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+	return func(c *fiber.Ctx) error {
+		res, err := l.Take(c.UserContext(), group, l.key(c))
+		if err != nil {
+			slog.WarnContext(c.UserContext(), "Rate limit store failed", "error", err, "group", group)
+			return c.Next()
+		}
+
+		c.Set(HeaderLimit, strconv.Itoa(limit.Burst))
+		c.Set(HeaderRemaining, strconv.Itoa(res.Remaining))
+		c.Set(HeaderReset, strconv.Itoa(seconds(res.Reset)))
+		if !res.Allowed {
+			retryAfter := seconds(res.RetryAfter)
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			return fiber.NewError(fiber.StatusTooManyRequests,
+				fmt.Sprintf("Rate limit exceeded, retry in %d seconds", retryAfter))
+		}
+		return c.Next()
+	}
+}
+
+// seconds rounds d up to whole seconds.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
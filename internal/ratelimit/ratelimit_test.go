@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/auth"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Limit
+		wantErr bool
+	}{
+		{input: "", want: Limit{}},
+		{input: "10/s", want: Limit{Rate: 10, Burst: 10}},
+		{input: "600/m:20", want: Limit{Rate: 10, Burst: 20}},
+		{input: "3600/h", want: Limit{Rate: 1, Burst: 3600}},
+		{input: "10", wantErr: true},
+		{input: "10/d", wantErr: true},
+		{input: "-1/s", wantErr: true},
+		{input: "10/s:0", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimit(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 2}
+	ctx := context.Background()
+
+	for i, wantRemaining := range []int{1, 0} {
+		res, _ := store.Take(ctx, "a", limit)
+		if !res.Allowed || res.Remaining != wantRemaining {
+			t.Fatalf("take %d: got %+v, want allowed with %d remaining", i, res, wantRemaining)
+		}
+	}
+	res, _ := store.Take(ctx, "a", limit)
+	if res.Allowed || res.RetryAfter != time.Second || res.Reset != 2*time.Second {
+		t.Errorf("expected an empty bucket refilling in 1s, got %+v", res)
+	}
+	if res, _ := store.Take(ctx, "b", limit); !res.Allowed {
+		t.Errorf("expected other clients to have their own bucket")
+	}
+
+	// The bucket refills over time, and full buckets are dropped
+	now = now.Add(500 * time.Millisecond)
+	if res, _ := store.Take(ctx, "a", limit); res.Allowed {
+		t.Errorf("expected half a token not to be enough, got %+v", res)
+	}
+	now = now.Add(sweepInterval)
+	if res, _ := store.Take(ctx, "a", limit); !res.Allowed || res.Remaining != 1 {
+		t.Errorf("expected a full bucket after a while, got %+v", res)
+	}
+	if _, ok := store.buckets["b"]; ok {
+		t.Errorf("expected the idle bucket to be dropped")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	limiter := New(NewMemoryStore(), map[string]Limit{GroupExport: {Rate: 1.0 / 60, Burst: 1}})
+	app := fiber.New()
+	app.Get("/export", limiter.Middleware(GroupExport), func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	app.Get("/search", limiter.Middleware(GroupSearch), func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	keyring, err := auth.NewKeyring([]auth.Key{{Label: "partner", SHA256: auth.Hash("secret"), Scopes: []string{auth.ScopeExport}}})
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %v", err)
+	}
+	app.Get("/keyed/export", keyring.Middleware(auth.ScopeExport), limiter.Middleware(GroupExport), func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/export", nil))
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get(HeaderLimit) != "1" || resp.Header.Get(HeaderRemaining) != "0" {
+		t.Errorf("unexpected first response: %d %v", resp.StatusCode, resp.Header)
+	}
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/export", nil))
+	if resp.StatusCode != fiber.StatusTooManyRequests || resp.Header.Get(fiber.HeaderRetryAfter) != "60" {
+		t.Errorf("expected 429 with Retry-After: 60, got %d %v", resp.StatusCode, resp.Header)
+	}
+
+	// Keys that were not verified do not give a bucket of their own
+	for _, key := range []string{"random-1", "random-2"} {
+		req := httptest.NewRequest("GET", "/export", nil)
+		req.Header.Set(auth.HeaderAPIKey, key)
+		if resp, _ := app.Test(req); resp.StatusCode != fiber.StatusTooManyRequests {
+			t.Errorf("expected the unknown key %s to share the IP bucket, got %d", key, resp.StatusCode)
+		}
+	}
+
+	// Clients authenticated with an API key have a bucket of their own
+	req := httptest.NewRequest("GET", "/keyed/export", nil)
+	req.Header.Set(auth.HeaderAPIKey, "secret")
+	if resp, _ := app.Test(req); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("expected the API key client not to be limited, got %d", resp.StatusCode)
+	}
+
+	// Groups without a limit are not limited and carry no headers
+	for i := 0; i < 3; i++ {
+		resp, _ = app.Test(httptest.NewRequest("GET", "/search", nil))
+		if resp.StatusCode != fiber.StatusOK || resp.Header.Get(HeaderLimit) != "" {
+			t.Errorf("expected an unlimited response, got %d %v", resp.StatusCode, resp.Header)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"database/sql"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	_ "github.com/marcboeker/go-duckdb"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ilmimris/wilayah-indonesia/internal/api"
+	"github.com/ilmimris/wilayah-indonesia/pkg/service"
+)
+
+func TestMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE regions (id VARCHAR, subdistrict VARCHAR, district VARCHAR, city VARCHAR,
+			province VARCHAR, postal_code VARCHAR, full_text VARCHAR);
+		INSERT INTO regions VALUES
+			('32.73.01.1001', 'Sarijadi', 'Sukasari', 'Kota Bandung', 'Jawa Barat', '40151', ''),
+			('31.71.01.1001', 'Gambir', 'Gambir', 'Kota Adm. Jakarta Pusat', 'DKI Jakarta', '10110', '');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create regions: %v", err)
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	app.Use(Middleware())
+	api.RegisterRoutes(app, api.New(service.New(db)))
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("GET", "/v1/search/province?q=Jawa+Barat", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+		if got := span.SpanContext().TraceID().String(); got != traceID {
+			t.Errorf("span %s has trace ID %s, expected the caller's %s", span.Name(), got, traceID)
+		}
+	}
+
+	server, ok := spans["GET /v1/search/province"]
+	if !ok {
+		t.Fatalf("no server span named after the route, got %v", spans)
+	}
+	method, ok := spans["Service.SearchPage"]
+	if !ok {
+		t.Fatalf("no span for the service method, got %v", spans)
+	}
+	query, ok := spans["duckdb.query"]
+	if !ok {
+		t.Fatalf("no span for the DuckDB query, got %v", spans)
+	}
+	if method.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Errorf("service span is not a child of the server span")
+	}
+	if query.Parent().SpanID() != method.SpanContext().SpanID() {
+		t.Errorf("query span is not a child of the service span")
+	}
+
+	attrs := map[string]interface{}{}
+	for _, kv := range method.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	if attrs[string(service.AttrSearchKind)] != service.SearchProvince || attrs[string(service.AttrResultCount)] != int64(1) {
+		t.Errorf("unexpected service span attributes: %v", attrs)
+	}
+}
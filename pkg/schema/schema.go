@@ -0,0 +1,40 @@
+// Package schema names the tables and keys of the dataset, which the
+// ingestor writes and the service reads. It has no dependencies so that both
+// can import it.
+package schema
+
+// MetadataTable is the key/value table written by the ingestor to describe the dataset build.
+const MetadataTable = "dataset_metadata"
+
+// Metadata keys stored in MetadataTable.
+const (
+	MetadataVersion = "version"
+	MetadataBuiltAt = "built_at"
+)
+
+// DatasetVersionQuery hashes the contents of the regions table so that two
+// builds with the same data share a version.
+const DatasetVersionQuery = `
+	SELECT SUBSTRING(md5(string_agg(
+		id || '|' || COALESCE(subdistrict, '') || '|' || COALESCE(district, '') || '|' ||
+		COALESCE(city, '') || '|' || COALESCE(province, '') || '|' || COALESCE(postal_code, ''),
+		chr(10) ORDER BY id
+	)) FROM 1 FOR 16)
+	FROM regions
+`
+
+// IslandsTable holds the islands written by the ingestor when the upstream
+// island dump is given. It is indexed for full-text search like regions.
+const IslandsTable = "islands"
+
+// RegionStatsTable holds the coordinates, area, population and boundaries of
+// provinces and cities, written by the ingestor when the upstream dumps are given.
+const RegionStatsTable = "region_stats"
+
+// AttributeSetsTable is the registry of attribute sets written by the ingestor.
+// Each row maps a set name to the side table holding its values and tells
+// whether regions inherit the values of their ancestors.
+const AttributeSetsTable = "attribute_sets"
+
+// AttributeTablePrefix prefixes the name of every attribute side table.
+const AttributeTablePrefix = "attr_"
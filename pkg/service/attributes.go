@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/schema"
+)
+
+// AttributeSetsTable is the registry of attribute sets written by the ingestor.
+// Each row maps a set name to the side table holding its values.
+const AttributeSetsTable = schema.AttributeSetsTable
+
+// AttributeTablePrefix prefixes the name of every attribute side table.
+const AttributeTablePrefix = schema.AttributeTablePrefix
+
+// AttributeSourceCode is the attribute holding the code of the ancestor an
+// inherited attribute row belongs to.
+const AttributeSourceCode = "source_code"
+
+// attributeSet is an attribute set of the registry.
+type attributeSet struct {
+	table string
+
+	// inherit lets regions without a row of their own receive the row of
+	// their closest ancestor.
+	inherit bool
+}
+
+// AttributeSets returns the names of the attribute sets available in the
+// database, sorted by name. A database without attribute sets returns an
+// empty list.
+func (s *Service) AttributeSets(ctx context.Context) ([]string, error) {
+	ctx, span := startSpan(ctx, "AttributeSets")
+	sets, err := s.attributeSets(ctx)
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(sets))
+	for name := range sets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// attributeSets returns the registry keyed by set name. The registry is
+// cached because the database is opened read-only.
+func (s *Service) attributeSets(ctx context.Context) (map[string]attributeSet, error) {
+	s.attributesMu.Lock()
+	defer s.attributesMu.Unlock()
+
+	s.observer.ObserveCache(CacheAttributeSets, s.attributes != nil)
+	if s.attributes != nil {
+		return s.attributes, nil
+	}
+
+	sets := make(map[string]attributeSet)
+	exists, err := s.tableExists(ctx, AttributeSetsTable)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		rows, err := s.query(ctx, "AttributeSets", "SELECT name, table_name, inherit FROM "+AttributeSetsTable)
+		if err != nil {
+			return nil, WrapError(ErrCodeDatabaseFailure, err, "failed to read attribute sets")
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var name string
+			var set attributeSet
+			if err := rows.Scan(&name, &set.table, &set.inherit); err != nil {
+				return nil, WrapError(ErrCodeDatabaseFailure, err, "failed to scan attribute set")
+			}
+			sets[name] = set
+		}
+		if err := rows.Err(); err != nil {
+			return nil, WrapError(ErrCodeDatabaseFailure, err, "error iterating attribute sets")
+		}
+	}
+
+	s.attributes = sets
+	return sets, nil
+}
+
+// WithAttributes attaches the requested attribute sets to each region. A
+// region receives the row keyed by its own code. Sets registered as
+// inheritable, such as phone area codes, fall back to the row of the closest
+// ancestor, so a province-level attribute applies to every region in the
+// province; such rows carry the code they belong to as AttributeSourceCode.
+// Unknown set names are rejected with ErrCodeInvalidInput.
+func (s *Service) WithAttributes(ctx context.Context, regions []Region, include []string) (err error) {
+	if len(include) == 0 || len(regions) == 0 {
+		return nil
+	}
+
+	ctx, span := startSpan(ctx, "WithAttributes", AttrResultCount.Int(len(regions)))
+	defer func() { endSpan(span, err) }()
+
+	sets, err := s.attributeSets(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range include {
+		if _, ok := sets[name]; !ok {
+			return NewFieldError("include", fmt.Sprintf("unknown attribute set %q", name))
+		}
+	}
+
+	for _, name := range include {
+		set := sets[name]
+		s.logger.DebugContext(ctx, "Processing attribute request", "set", name, "regions", len(regions), "inherit", set.inherit)
+
+		// Collect every code a region may take attributes from
+		lineage := func(code string) []string {
+			if set.inherit {
+				return codeLineage(code)
+			}
+			return []string{code}
+		}
+		var codes []interface{}
+		seen := make(map[string]bool)
+		for _, region := range regions {
+			for _, code := range lineage(region.ID) {
+				if !seen[code] {
+					seen[code] = true
+					codes = append(codes, code)
+				}
+			}
+		}
+
+		sqlQuery := `SELECT * FROM "` + set.table + `" WHERE code IN (` + placeholders(len(codes)) + `)`
+		rows, err := s.query(ctx, "WithAttributes", sqlQuery, codes...)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Database query failed", "error", err, "set", name)
+			return WrapError(ErrCodeDatabaseFailure, err, "database query failed")
+		}
+		values, err := s.scanAttributes(ctx, rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		for i := range regions {
+			for _, code := range lineage(regions[i].ID) {
+				if attrs, ok := values[code]; ok {
+					if code != regions[i].ID {
+						attrs = inheritedFrom(attrs, code)
+					}
+					if regions[i].Attributes == nil {
+						regions[i].Attributes = make(map[string]map[string]interface{})
+					}
+					regions[i].Attributes[name] = attrs
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// scanAttributes reads attribute rows into a map keyed by region code.
+func (s *Service) scanAttributes(ctx context.Context, rows *sql.Rows) (map[string]map[string]interface{}, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, WrapError(ErrCodeDatabaseFailure, err, "failed to get columns")
+	}
+
+	values := make(map[string]map[string]interface{})
+	for rows.Next() {
+		dest := make([]interface{}, len(cols))
+		scanArgs := make([]interface{}, len(cols))
+		for i := range dest {
+			scanArgs[i] = &dest[i]
+		}
+		if err := rows.Scan(scanArgs...); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to scan row", "error", err)
+			return nil, WrapError(ErrCodeDatabaseFailure, err, "failed to scan row")
+		}
+
+		var code string
+		attrs := make(map[string]interface{}, len(cols)-1)
+		for i, col := range cols {
+			if col == "code" {
+				code, _ = dest[i].(string)
+				continue
+			}
+			attrs[col] = dest[i]
+		}
+		values[code] = attrs
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "Error iterating rows", "error", err)
+		return nil, WrapError(ErrCodeDatabaseFailure, err, "error iterating rows")
+	}
+	return values, nil
+}
+
+// inheritedFrom returns a copy of the attributes of an ancestor marked with
+// its code.
+func inheritedFrom(attrs map[string]interface{}, code string) map[string]interface{} {
+	inherited := make(map[string]interface{}, len(attrs)+1)
+	for k, v := range attrs {
+		inherited[k] = v
+	}
+	inherited[AttributeSourceCode] = code
+	return inherited
+}
+
+// codeLineage returns a region code followed by the codes of its ancestors,
+// most specific first: 32.73.01.1001, 32.73.01, 32.73, 32.
+func codeLineage(code string) []string {
+	lineage := []string{code}
+	for i := len(code) - 1; i > 0; i-- {
+		if code[i] == '.' {
+			lineage = append(lineage, code[:i])
+		}
+	}
+	return lineage
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+)
+
+// searchKey identifies a page of search results.
+type searchKey struct {
+	kind   string
+	query  string
+	limit  int
+	offset int
+	fields string
+}
+
+// lruCache is a fixed-size cache that evicts the least recently used entry.
+// It is safe for concurrent use.
+type lruCache[K comparable, V any] struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[K]*list.Element
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func newLRUCache[K comparable, V any](size int) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[K]*list.Element, size),
+	}
+}
+
+// get returns the value cached for key and marks it as recently used.
+func (c *lruCache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*lruEntry[K, V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// add caches value for key, evicting the least recently used entry when the
+// cache is full.
+func (c *lruCache[K, V]) add(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry[K, V]).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
@@ -1,19 +1,41 @@
 // Package service provides business logic for the wilayah-indonesia API.
 package service
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
-// Error represents a service error with a code and message.
+// Error represents a service error with a code and message. It may wrap the
+// underlying cause, such as a database error, which is reachable through
+// errors.Is and errors.As but kept out of Message so that Message is safe to
+// show to API clients.
 type Error struct {
 	Code    string
 	Message string
+	Details []FieldError
+	Cause   error
+}
+
+// FieldError describes a problem with a single input field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
-// Error returns the error message.
+// Error returns the error message, followed by the cause when there is one.
 func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
 	return e.Message
 }
 
+// Unwrap returns the underlying cause of the error.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
 // Error codes
 const (
 	ErrCodeInvalidInput    = "INVALID_INPUT"
@@ -37,12 +59,37 @@ func NewErrorf(code string, format string, args ...interface{}) *Error {
 	}
 }
 
-// IsError checks if an error is a service error with the specified code.
-func IsError(err error, code string) bool {
-	if err == nil {
-		return false
+// WrapError creates a new service error with the specified code and message wrapping cause.
+func WrapError(code string, cause error, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+		Cause:   cause,
+	}
+}
+
+// WrapErrorf creates a new service error with the specified code and formatted message wrapping cause.
+func WrapErrorf(code string, cause error, format string, args ...interface{}) *Error {
+	return &Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		Cause:   cause,
+	}
+}
+
+// NewFieldError creates an invalid input error for a single input field.
+func NewFieldError(field string, message string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidInput,
+		Message: message,
+		Details: []FieldError{{Field: field, Message: message}},
 	}
-	if svcErr, ok := err.(*Error); ok {
+}
+
+// IsError checks if an error is, or wraps, a service error with the specified code.
+func IsError(err error, code string) bool {
+	var svcErr *Error
+	if errors.As(err, &svcErr) {
 		return svcErr.Code == code
 	}
 	return false
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorWrapping(t *testing.T) {
+	err := WrapError(ErrCodeDatabaseFailure, sql.ErrConnDone, "database query failed")
+	if err.Error() != "database query failed: "+sql.ErrConnDone.Error() {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+	if !errors.Is(err, sql.ErrConnDone) {
+		t.Error("errors.Is did not find the wrapped cause")
+	}
+
+	// Service errors stay recognizable when wrapped again
+	wrapped := fmt.Errorf("export: %w", err)
+	if !IsError(wrapped, ErrCodeDatabaseFailure) {
+		t.Error("IsError did not match a wrapped service error")
+	}
+	var svcErr *Error
+	if !errors.As(wrapped, &svcErr) || svcErr.Message != "database query failed" {
+		t.Errorf("errors.As returned %v", svcErr)
+	}
+
+	fieldErr := NewFieldError("code", "invalid region code")
+	if !IsError(fieldErr, ErrCodeInvalidInput) || len(fieldErr.Details) != 1 || fieldErr.Details[0].Field != "code" {
+		t.Errorf("unexpected field error: %+v", fieldErr)
+	}
+	if IsError(nil, ErrCodeInvalidInput) {
+		t.Error("IsError matched a nil error")
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// Administrative levels accepted by Export.
+const (
+	LevelProvince    = "province"
+	LevelCity        = "city"
+	LevelDistrict    = "district"
+	LevelSubdistrict = "subdistrict"
+)
+
+// ExportLevels lists the administrative levels accepted by Export.
+var ExportLevels = []string{LevelProvince, LevelCity, LevelDistrict, LevelSubdistrict}
+
+// levelQueries selects every region of a level with its code in a column
+// named code and the other columns named after the Region fields. Levels
+// above subdistrict are derived from the denormalized regions table, leaving
+// the lower-level fields empty.
+var levelQueries = map[string]string{
+	LevelProvince: `
+		SELECT SUBSTRING(id FROM 1 FOR 2) AS code, '' AS subdistrict, '' AS district, '' AS city,
+			MIN(province) AS province, '' AS postal_code, LOWER(MIN(province)) AS full_text
+		FROM regions
+		GROUP BY code
+	`,
+	LevelCity: `
+		SELECT SUBSTRING(id FROM 1 FOR 5) AS code, '' AS subdistrict, '' AS district, MIN(city) AS city,
+			MIN(province) AS province, '' AS postal_code, LOWER(MIN(province) || ' ' || MIN(city)) AS full_text
+		FROM regions
+		GROUP BY code
+	`,
+	LevelDistrict: `
+		SELECT SUBSTRING(id FROM 1 FOR 8) AS code, '' AS subdistrict, MIN(district) AS district, MIN(city) AS city,
+			MIN(province) AS province, '' AS postal_code, LOWER(MIN(province) || ' ' || MIN(city) || ' ' || MIN(district)) AS full_text
+		FROM regions
+		GROUP BY code
+	`,
+	LevelSubdistrict: `
+		SELECT id AS code, subdistrict, district, city, province, COALESCE(postal_code, '') AS postal_code, full_text
+		FROM regions
+	`,
+}
+
+// Export streams every region at the given level, ordered by code, to fn
+// without loading the whole result into memory. An empty level exports
+// subdistricts. Iteration stops at the first error returned by fn.
+func (s *Service) Export(ctx context.Context, level string, fn func(Region) error) (err error) {
+	if level == "" {
+		level = LevelSubdistrict
+	}
+	ctx, span := startSpan(ctx, "Export", AttrLevel.String(level))
+	defer func() { endSpan(span, err) }()
+
+	sqlQuery, ok := levelQueries[level]
+	if !ok {
+		return NewFieldError("level", fmt.Sprintf("unknown level %q", level))
+	}
+	sqlQuery += " ORDER BY code"
+
+	s.logger.DebugContext(ctx, "Processing export request", "level", level)
+
+	// Load the stats before streaming so the lookups below hit the cache
+	if level == LevelProvince || level == LevelCity {
+		if _, err := s.regionStats(ctx); err != nil {
+			return err
+		}
+	}
+
+	rows, err := s.query(ctx, "Export", sqlQuery)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Database query failed", "error", err, "level", level)
+		return WrapError(ErrCodeDatabaseFailure, err, "database query failed")
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var region Region
+		err := rows.Scan(&region.ID, &region.Subdistrict, &region.District, &region.City,
+			&region.Province, &region.PostalCode, &region.FullText)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to scan row", "error", err)
+			return WrapError(ErrCodeDatabaseFailure, err, "failed to scan row")
+		}
+		if level == LevelProvince || level == LevelCity {
+			if err := s.withStats(ctx, &region); err != nil {
+				return err
+			}
+		}
+		if err := fn(region); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "Error iterating rows", "error", err)
+		return WrapError(ErrCodeDatabaseFailure, err, "error iterating rows")
+	}
+
+	span.SetAttributes(AttrResultCount.Int(count))
+	s.logger.InfoContext(ctx, "Export completed", "level", level, "results", count)
+	return nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Region fields, named as in JSON, that can be selected with
+// SearchOptions.Fields and GetByCodeFields.
+const (
+	FieldID          = "id"
+	FieldSubdistrict = "subdistrict"
+	FieldDistrict    = "district"
+	FieldCity        = "city"
+	FieldProvince    = "province"
+	FieldPostalCode  = "postal_code"
+	FieldFullText    = "full_text"
+	FieldLatitude    = "latitude"
+	FieldLongitude   = "longitude"
+	FieldAreaKm2     = "area_km2"
+	FieldPopulation  = "population"
+)
+
+// RegionFields lists the selectable region fields in the order of Region.
+var RegionFields = []string{
+	FieldID, FieldSubdistrict, FieldDistrict, FieldCity, FieldProvince, FieldPostalCode, FieldFullText,
+	FieldLatitude, FieldLongitude, FieldAreaKm2, FieldPopulation,
+}
+
+// statsFields are the region fields read from the region stats rather than
+// from a column.
+var statsFields = []string{FieldLatitude, FieldLongitude, FieldAreaKm2, FieldPopulation}
+
+// fieldColumn binds a region field to the SQL expression it is read from in
+// the searches. levelQueries name their columns after the fields, except for
+// the id column, named code.
+type fieldColumn struct {
+	field       string
+	search      string
+	destination func(*Region) interface{}
+}
+
+// fieldColumns holds the region fields read from a column, in column order.
+var fieldColumns = []fieldColumn{
+	{FieldID, "id", func(r *Region) interface{} { return &r.ID }},
+	{FieldSubdistrict, "subdistrict", func(r *Region) interface{} { return &r.Subdistrict }},
+	{FieldDistrict, "district", func(r *Region) interface{} { return &r.District }},
+	{FieldCity, "city", func(r *Region) interface{} { return &r.City }},
+	{FieldProvince, "province", func(r *Region) interface{} { return &r.Province }},
+	{FieldPostalCode, "COALESCE(postal_code, '')", func(r *Region) interface{} { return &r.PostalCode }},
+	{FieldFullText, "full_text", func(r *Region) interface{} { return &r.FullText }},
+}
+
+// validateFields rejects unknown region fields. No fields select them all.
+func validateFields(fields []string) error {
+	for _, field := range fields {
+		if !slices.Contains(RegionFields, field) {
+			return NewFieldError("fields", fmt.Sprintf("unknown field %q, must be one of %s", field, strings.Join(RegionFields, ", ")))
+		}
+	}
+	return nil
+}
+
+// selectedColumns returns the columns to read for the selected fields, with
+// the destinations of a region to scan them into. The id is always read,
+// since attributes are looked up by it. The level column names are used when
+// level is set.
+func selectedColumns(fields []string, level bool) (columns []string, destinations func(*Region) []interface{}) {
+	var selected []fieldColumn
+	for _, fc := range fieldColumns {
+		if fc.field == FieldID || len(fields) == 0 || slices.Contains(fields, fc.field) {
+			selected = append(selected, fc)
+		}
+	}
+	for _, fc := range selected {
+		switch {
+		case !level:
+			columns = append(columns, fc.search)
+		case fc.field == FieldID:
+			columns = append(columns, "code")
+		default:
+			columns = append(columns, fc.field)
+		}
+	}
+	return columns, func(r *Region) []interface{} {
+		dest := make([]interface{}, len(selected))
+		for i, fc := range selected {
+			dest[i] = fc.destination(r)
+		}
+		return dest
+	}
+}
+
+// selectsStats reports whether the selected fields include a region stat.
+func selectsStats(fields []string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	for _, field := range statsFields {
+		if slices.Contains(fields, field) {
+			return true
+		}
+	}
+	return false
+}
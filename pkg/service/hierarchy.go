@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// codeLengths holds the length of the codes at each level.
+var codeLengths = map[string]int{
+	LevelProvince:    2,
+	LevelCity:        5,
+	LevelDistrict:    8,
+	LevelSubdistrict: 13,
+}
+
+// childLevels maps each level to the level directly below it. The empty
+// level is the root above provinces.
+var childLevels = map[string]string{
+	"":            LevelProvince,
+	LevelProvince: LevelCity,
+	LevelCity:     LevelDistrict,
+	LevelDistrict: LevelSubdistrict,
+}
+
+// RegionsByCode returns the regions with the given codes, which may mix
+// levels, keyed by code. Unknown codes are left out of the result. Codes of
+// the same level are fetched with a single query.
+func (s *Service) RegionsByCode(ctx context.Context, codes []string) (results map[string]Region, err error) {
+	ctx, span := startSpan(ctx, "RegionsByCode")
+	defer func() { endSpan(span, err) }()
+
+	byLevel, err := groupByLevel(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.DebugContext(ctx, "Processing regions by code request", "codes", len(codes))
+
+	results = make(map[string]Region, len(codes))
+	for level, levelCodes := range byLevel {
+		if level == "" {
+			continue
+		}
+		sqlQuery := "SELECT * FROM (" + levelQueries[level] + ") WHERE code IN (" + placeholders(len(levelCodes)) + ")"
+		regions, err := s.queryLevel(ctx, "RegionsByCode", level, sqlQuery, levelCodes)
+		if err != nil {
+			return nil, err
+		}
+		for _, region := range regions {
+			results[region.ID] = region
+		}
+	}
+
+	span.SetAttributes(AttrResultCount.Int(len(results)))
+	s.logger.InfoContext(ctx, "Regions by code request completed", "codes", len(codes), "results", len(results))
+	return results, nil
+}
+
+// ChildrenOf returns the regions directly below each parent code, ordered by
+// code and keyed by parent code. The empty parent code lists the provinces.
+// Parents of the same level are served by a single query.
+func (s *Service) ChildrenOf(ctx context.Context, parents []string) (results map[string][]Region, err error) {
+	ctx, span := startSpan(ctx, "ChildrenOf")
+	defer func() { endSpan(span, err) }()
+
+	byLevel, err := groupByLevel(parents)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.DebugContext(ctx, "Processing children request", "parents", len(parents))
+
+	results = make(map[string][]Region, len(parents))
+	count := 0
+	for level, levelCodes := range byLevel {
+		child, ok := childLevels[level]
+		if !ok {
+			return nil, NewFieldError("code", fmt.Sprintf("%s regions have no children", level))
+		}
+		sqlQuery := "SELECT * FROM (" + levelQueries[child] + ")"
+		args := levelCodes
+		if level == "" {
+			args = nil
+		} else {
+			sqlQuery += fmt.Sprintf(" WHERE SUBSTRING(code FROM 1 FOR %d) IN (%s)", codeLengths[level], placeholders(len(levelCodes)))
+		}
+		sqlQuery += " ORDER BY code"
+
+		regions, err := s.queryLevel(ctx, "ChildrenOf", child, sqlQuery, args)
+		if err != nil {
+			return nil, err
+		}
+		for _, region := range regions {
+			parent := ""
+			if level != "" {
+				parent = region.ID[:codeLengths[level]]
+			}
+			results[parent] = append(results[parent], region)
+		}
+		count += len(regions)
+	}
+
+	span.SetAttributes(AttrResultCount.Int(count))
+	s.logger.InfoContext(ctx, "Children request completed", "parents", len(parents), "results", count)
+	return results, nil
+}
+
+// CountChildren returns the number of regions directly below each parent
+// code. Parents of the same level are served by a single query.
+func (s *Service) CountChildren(ctx context.Context, parents []string) (results map[string]int, err error) {
+	ctx, span := startSpan(ctx, "CountChildren")
+	defer func() { endSpan(span, err) }()
+
+	byLevel, err := groupByLevel(parents)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.DebugContext(ctx, "Processing child count request", "parents", len(parents))
+
+	results = make(map[string]int, len(parents))
+	for level, levelCodes := range byLevel {
+		child, ok := childLevels[level]
+		if !ok {
+			return nil, NewFieldError("code", fmt.Sprintf("%s regions have no children", level))
+		}
+		n := codeLengths[level]
+		sqlQuery := fmt.Sprintf(`
+			SELECT SUBSTRING(code FROM 1 FOR %d) AS parent, COUNT(*)
+			FROM (%s)
+			WHERE SUBSTRING(code FROM 1 FOR %d) IN (%s)
+			GROUP BY parent
+		`, n, levelQueries[child], n, placeholders(len(levelCodes)))
+		if level == "" {
+			sqlQuery = "SELECT '', COUNT(*) FROM (" + levelQueries[child] + ")"
+			levelCodes = nil
+		}
+
+		rows, err := s.query(ctx, "CountChildren", sqlQuery, stringArgs(levelCodes)...)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Database query failed", "error", err, "level", level)
+			return nil, WrapError(ErrCodeDatabaseFailure, err, "database query failed")
+		}
+		for rows.Next() {
+			var parent string
+			var count int
+			if err := rows.Scan(&parent, &count); err != nil {
+				rows.Close()
+				s.logger.ErrorContext(ctx, "Failed to scan row", "error", err)
+				return nil, WrapError(ErrCodeDatabaseFailure, err, "failed to scan row")
+			}
+			results[parent] = count
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Error iterating rows", "error", err)
+			return nil, WrapError(ErrCodeDatabaseFailure, err, "error iterating rows")
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Child count request completed", "parents", len(parents))
+	return results, nil
+}
+
+// queryLevel runs a query over levelQueries[level] on behalf of method and
+// scans the regions, adding the stats of provinces and cities.
+func (s *Service) queryLevel(ctx context.Context, method, level, sqlQuery string, codes []string) ([]Region, error) {
+	rows, err := s.query(ctx, method, sqlQuery, stringArgs(codes)...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Database query failed", "error", err, "level", level)
+		return nil, WrapError(ErrCodeDatabaseFailure, err, "database query failed")
+	}
+	defer rows.Close()
+
+	var results []Region
+	for rows.Next() {
+		var region Region
+		err := rows.Scan(&region.ID, &region.Subdistrict, &region.District, &region.City,
+			&region.Province, &region.PostalCode, &region.FullText)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to scan row", "error", err)
+			return nil, WrapError(ErrCodeDatabaseFailure, err, "failed to scan row")
+		}
+		results = append(results, region)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "Error iterating rows", "error", err)
+		return nil, WrapError(ErrCodeDatabaseFailure, err, "error iterating rows")
+	}
+
+	if level == LevelProvince || level == LevelCity {
+		for i := range results {
+			if err := s.withStats(ctx, &results[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// groupByLevel validates codes and groups them by level without duplicates.
+// The empty code is grouped under the empty level.
+func groupByLevel(codes []string) (map[string][]string, error) {
+	byLevel := make(map[string][]string)
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		level := ""
+		if code != "" {
+			if level = LevelOfCode(code); level == "" {
+				return nil, NewFieldError("code", fmt.Sprintf("invalid region code %q", code))
+			}
+		}
+		byLevel[level] = append(byLevel[level], code)
+	}
+	return byLevel, nil
+}
+
+// placeholders returns n comma-separated ? placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// stringArgs converts strings to query arguments.
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/schema"
+)
+
+// IslandsTable holds the islands written by the ingestor when the upstream
+// island dump is given. It is indexed for full-text search like regions.
+const IslandsTable = schema.IslandsTable
+
+// Island represents an island and the city (regency) it belongs to.
+type Island struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	CityCode  string   `json:"city_code"`
+	City      string   `json:"city"`
+	Province  string   `json:"province"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	FullText  string   `json:"full_text"`
+}
+
+// SearchIslands performs a fuzzy search of islands by name, combining the
+// full-text index with Jaro-Winkler similarity so that misspelt names match.
+func (s *Service) SearchIslands(query string) ([]Island, error) {
+	page, err := s.SearchIslandsPage(context.Background(), query, SearchOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchIslandsPage is SearchIslands returning one page of results with the
+// total number of matches.
+func (s *Service) SearchIslandsPage(ctx context.Context, query string, opts SearchOptions) (_ *Page[Island], err error) {
+	ctx, span := startSpan(ctx, "SearchIslandsPage")
+	defer func() { endSpan(span, err) }()
+
+	query = normalizeQuery(query)
+	if query == "" {
+		return nil, NewFieldError("query", "query parameter is required")
+	}
+	opts, err = opts.normalize(s.defaultLimit, s.maxLimit)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireIslands(ctx); err != nil {
+		return nil, err
+	}
+
+	s.logger.DebugContext(ctx, "Processing island search request", "query", query, "limit", opts.Limit, "offset", opts.Offset)
+
+	// Prepare and execute the SQL query for Full-Text Search
+	sqlQuery := `
+		SELECT id, name, city_code, city, province, latitude, longitude, full_text, COUNT(*) OVER () AS total
+		FROM (
+			SELECT *,
+				fts_main_islands.match_bm25(id, ?) AS score,
+				jaro_winkler_similarity(LOWER(name), LOWER(?)) AS similarity
+			FROM islands
+		)
+		WHERE score IS NOT NULL OR similarity >= ` + s.similarity(SimilarityIslands) + `
+		ORDER BY score DESC NULLS LAST, similarity DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := s.query(ctx, "SearchIslands", sqlQuery, query, query, opts.Limit, opts.Offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Database query failed", "error", err, "query", query)
+		return nil, WrapError(ErrCodeDatabaseFailure, err, "database query failed")
+	}
+	defer rows.Close()
+
+	// Iterate through the results
+	results, total, err := s.scanIslands(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	s.observer.ObserveSearch("SearchIslands", total)
+	span.SetAttributes(AttrResultCount.Int(len(results)), AttrTotal.Int(total))
+
+	s.logger.InfoContext(ctx, "Island search completed", "query", query, "results", len(results), "total", total)
+	return &Page[Island]{Items: results, Total: total, Limit: opts.Limit, Offset: opts.Offset, Query: query}, nil
+}
+
+// IslandsByCity lists the islands of a city (regency) ordered by name.
+func (s *Service) IslandsByCity(ctx context.Context, code string) (_ []Island, err error) {
+	ctx, span := startSpan(ctx, "IslandsByCity", AttrCode.String(code))
+	defer func() { endSpan(span, err) }()
+
+	if LevelOfCode(code) != LevelCity {
+		return nil, NewFieldError("code", fmt.Sprintf("invalid city code %q", code))
+	}
+	if err := s.requireIslands(ctx); err != nil {
+		return nil, err
+	}
+
+	s.logger.DebugContext(ctx, "Processing city islands request", "code", code)
+
+	var exists bool
+	if err := s.queryRow(ctx, "IslandsByCity", "SELECT COUNT(*) > 0 FROM regions WHERE id LIKE ? || '.%'", code).Scan(&exists); err != nil {
+		s.logger.ErrorContext(ctx, "Database query failed", "error", err, "code", code)
+		return nil, WrapError(ErrCodeDatabaseFailure, err, "database query failed")
+	}
+	if !exists {
+		return nil, NewError(ErrCodeNotFound, "no city found for the provided code")
+	}
+
+	// Prepare and execute the SQL query
+	sqlQuery := `
+		SELECT id, name, city_code, city, province, latitude, longitude, full_text, COUNT(*) OVER () AS total
+		FROM islands
+		WHERE city_code = ?
+		ORDER BY name, id
+	`
+
+	rows, err := s.query(ctx, "IslandsByCity", sqlQuery, code)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Database query failed", "error", err, "code", code)
+		return nil, WrapError(ErrCodeDatabaseFailure, err, "database query failed")
+	}
+	defer rows.Close()
+
+	results, _, err := s.scanIslands(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(AttrResultCount.Int(len(results)))
+	s.logger.InfoContext(ctx, "City islands request completed", "code", code, "results", len(results))
+	return results, nil
+}
+
+// requireIslands returns ErrCodeNotFound when the database was built without island data.
+func (s *Service) requireIslands(ctx context.Context) error {
+	exists, err := s.tableExists(ctx, IslandsTable)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return NewError(ErrCodeNotFound, "island data is not available")
+	}
+	return nil
+}
+
+// scanIslands iterates through the SQL rows and converts them to Island
+// structs. The rows carry the total number of matches in a last column.
+func (s *Service) scanIslands(ctx context.Context, rows *sql.Rows) ([]Island, int, error) {
+	results := []Island{}
+	total := 0
+	for rows.Next() {
+		var island Island
+		var lat, lng sql.NullFloat64
+		err := rows.Scan(&island.ID, &island.Name, &island.CityCode, &island.City,
+			&island.Province, &lat, &lng, &island.FullText, &total)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to scan row", "error", err)
+			return nil, 0, WrapError(ErrCodeDatabaseFailure, err, "failed to scan row")
+		}
+		island.Latitude = nullFloat(lat)
+		island.Longitude = nullFloat(lng)
+		results = append(results, island)
+	}
+
+	// Check for errors during iteration
+	if err := rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "Error iterating rows", "error", err)
+		return nil, 0, WrapError(ErrCodeDatabaseFailure, err, "error iterating rows")
+	}
+
+	return results, total, nil
+}
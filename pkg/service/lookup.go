@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// codePattern matches a Kemendagri code at any level, from province (32) to
+// subdistrict (32.73.01.1001).
+var codePattern = regexp.MustCompile(`^\d{2}(\.\d{2}(\.\d{2}(\.\d{4})?)?)?$`)
+
+// LevelOfCode returns the administrative level of a Kemendagri code, or an
+// empty string when the code is malformed.
+func LevelOfCode(code string) string {
+	if !codePattern.MatchString(code) {
+		return ""
+	}
+	switch len(code) {
+	case 2:
+		return LevelProvince
+	case 5:
+		return LevelCity
+	case 8:
+		return LevelDistrict
+	default:
+		return LevelSubdistrict
+	}
+}
+
+// GetByCode returns the region identified by a Kemendagri code at any level.
+// Regions above subdistrict have their lower-level fields left empty, as in Export.
+func (s *Service) GetByCode(ctx context.Context, code string) (*Region, error) {
+	return s.GetByCodeFields(ctx, code, nil)
+}
+
+// GetByCodeFields is GetByCode reading only the selected fields, as with
+// SearchOptions.Fields. The region stats are only read when one of their
+// fields is selected.
+func (s *Service) GetByCodeFields(ctx context.Context, code string, fields []string) (_ *Region, err error) {
+	ctx, span := startSpan(ctx, "GetByCode", AttrCode.String(code))
+	defer func() { endSpan(span, err) }()
+
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+	if code == "" {
+		return nil, NewFieldError("code", "code parameter is required")
+	}
+	level := LevelOfCode(code)
+	if level == "" {
+		return nil, NewFieldError("code", fmt.Sprintf("invalid region code %q", code))
+	}
+
+	s.logger.DebugContext(ctx, "Processing code lookup request", "code", code, "level", level)
+
+	// Prepare and execute the SQL query
+	columns, destinations := selectedColumns(fields, true)
+	sqlQuery := "SELECT " + strings.Join(columns, ", ") + " FROM (" + levelQueries[level] + ") WHERE code = ?"
+
+	var region Region
+	err = s.queryRow(ctx, "GetByCode", sqlQuery, code).Scan(destinations(&region)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.logger.InfoContext(ctx, "No region found for code", "code", code)
+		return nil, NewError(ErrCodeNotFound, "no region found for the provided code")
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Database query failed", "error", err, "code", code)
+		return nil, WrapError(ErrCodeDatabaseFailure, err, "database query failed")
+	}
+
+	if (level == LevelProvince || level == LevelCity) && selectsStats(fields) {
+		if err := s.withStats(ctx, &region); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Code lookup completed", "code", code, "level", level)
+	return &region, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/schema"
+)
+
+// MetadataTable is the key/value table written by the ingestor to describe the dataset build.
+const MetadataTable = schema.MetadataTable
+
+// Metadata keys stored in MetadataTable.
+const (
+	MetadataVersion = schema.MetadataVersion
+	MetadataBuiltAt = schema.MetadataBuiltAt
+)
+
+// ComputeDatasetVersion derives the dataset version from the contents of the regions table.
+func ComputeDatasetVersion(db *sql.DB) (string, error) {
+	var version sql.NullString
+	if err := db.QueryRow(schema.DatasetVersionQuery).Scan(&version); err != nil {
+		return "", WrapError(ErrCodeDatabaseFailure, err, "failed to compute dataset version")
+	}
+	return version.String, nil
+}
+
+// DatasetVersion returns the version of the loaded dataset. It is read from
+// the metadata written by the ingestor, or computed from the regions table for
+// databases built before the metadata existed. The value is cached because the
+// database is opened read-only.
+func (s *Service) DatasetVersion(ctx context.Context) (string, error) {
+	s.versionMu.Lock()
+	defer s.versionMu.Unlock()
+
+	s.observer.ObserveCache(CacheDatasetVersion, s.version != "")
+	if s.version != "" {
+		return s.version, nil
+	}
+
+	var version string
+	err := s.queryRow(ctx, "DatasetVersion", "SELECT value FROM "+MetadataTable+" WHERE key = ?", MetadataVersion).Scan(&version)
+	if err != nil {
+		s.logger.InfoContext(ctx, "Dataset metadata unavailable, computing version from regions", "error", err)
+		if version, err = ComputeDatasetVersion(s.db); err != nil {
+			return "", err
+		}
+	}
+
+	s.version = version
+	return version, nil
+}
+
+// tableExists reports whether the database has a table named name. Optional
+// tables are only written by the ingestor when their source data is given.
+func (s *Service) tableExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	err := s.queryRow(ctx, "tableExists", "SELECT COUNT(*) > 0 FROM information_schema.tables WHERE table_name = ?", name).Scan(&exists)
+	if err != nil {
+		return false, WrapErrorf(ErrCodeDatabaseFailure, err, "failed to look up table %s", name)
+	}
+	return exists, nil
+}
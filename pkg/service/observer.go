@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Cache names reported to Observer.ObserveCache.
+const (
+	CacheDatasetVersion = "dataset_version"
+	CacheAttributeSets  = "attribute_sets"
+	CacheRegionStats    = "region_stats"
+	CacheSearchResults  = "search_results"
+)
+
+// Observer receives measurements of the work done by the service, such as
+// query durations, so they can be exported as metrics. Its methods are
+// called concurrently.
+type Observer interface {
+	// ObserveQuery records the duration of a database query run on behalf
+	// of a Service method.
+	ObserveQuery(method string, duration time.Duration)
+
+	// ObserveSearch records the total number of matches of a search.
+	ObserveSearch(method string, total int)
+
+	// ObserveCache records a lookup in one of the service caches.
+	ObserveCache(cache string, hit bool)
+}
+
+// nopObserver is the Observer used when none is configured.
+type nopObserver struct{}
+
+func (nopObserver) ObserveQuery(string, time.Duration) {}
+func (nopObserver) ObserveSearch(string, int)          {}
+func (nopObserver) ObserveCache(string, bool)          {}
+
+// query runs a query in its own span and reports its duration for method.
+func (s *Service) query(ctx context.Context, method, sqlQuery string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, method, sqlQuery)
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	s.observer.ObserveQuery(method, time.Since(start))
+	endSpan(span, err)
+	return rows, err
+}
+
+// queryRow runs a query returning at most one row in its own span and
+// reports its duration for method.
+func (s *Service) queryRow(ctx context.Context, method, sqlQuery string, args ...interface{}) *sql.Row {
+	ctx, span := startQuerySpan(ctx, method, sqlQuery)
+	start := time.Now()
+	row := s.db.QueryRowContext(ctx, sqlQuery, args...)
+	s.observer.ObserveQuery(method, time.Since(start))
+	endSpan(span, row.Err())
+	return row
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Readiness check names.
+const (
+	CheckSchema      = "schema"
+	CheckRowCounts   = "row_counts"
+	CheckFullText    = "full_text_search"
+	CheckJaroWinkler = "jaro_winkler"
+	CheckVersion     = "dataset_version"
+)
+
+// regionColumns are the columns of the regions table read by the searches.
+var regionColumns = []string{"id", "subdistrict", "district", "city", "province", "postal_code", "full_text"}
+
+// countedTables are the tables whose rows are counted by Readiness. Only
+// regions is required.
+var countedTables = []string{"regions", IslandsTable, RegionStatsTable}
+
+// Canary queries run by Readiness. They must succeed but need not match.
+const (
+	fullTextCanary    = "SELECT COUNT(*) FROM (SELECT 1 FROM (SELECT fts_main_regions.match_bm25(id, 'jakarta') AS score FROM regions) WHERE score IS NOT NULL LIMIT 1)"
+	jaroWinklerCanary = "SELECT COUNT(*) FROM (SELECT id FROM regions WHERE jaro_winkler_similarity(city, 'Kota Bandung') >= 0.8 LIMIT 1)"
+)
+
+// Check is the outcome of one readiness check.
+type Check struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+// Readiness reports whether the loaded dataset can serve searches.
+type Readiness struct {
+	// Ready is set when every check passed.
+	Ready bool
+
+	Checks []Check
+
+	// DatasetVersion and RowCounts describe the dataset, as far as the
+	// checks could read them. Optional tables missing from the dataset
+	// are not counted.
+	DatasetVersion string
+	RowCounts      map[string]int64
+
+	// CheckedAt is when the checks ran, earlier than the call when the
+	// result was cached.
+	CheckedAt time.Time
+}
+
+// Readiness verifies that the regions table has the expected columns and
+// rows and that full-text and Jaro-Winkler queries run against it. Unlike
+// Ping, it fails when the dataset is incomplete, such as when the full-text
+// index was not built. Failed checks are reported in the result rather than
+// as an error. Concurrent calls share a single run of the checks, which
+// fail once the WithReadinessTimeout timeout expires. A successful result is
+// reused for the WithReadinessCache TTL.
+func (s *Service) Readiness(ctx context.Context) Readiness {
+	waiting := time.Now()
+	s.readinessMu.Lock()
+	defer s.readinessMu.Unlock()
+
+	// Reuse the result of the run that finished while waiting for the lock
+	r := s.readiness
+	if s.readinessDone.After(waiting) || (r.Ready && time.Since(r.CheckedAt) < s.readinessTTL) {
+		return r
+	}
+
+	if s.readinessTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.readinessTimeout)
+		defer cancel()
+	}
+	s.readiness = s.checkReadiness(ctx)
+	s.readinessDone = time.Now()
+	return s.readiness
+}
+
+// checkReadiness runs the readiness checks.
+func (s *Service) checkReadiness(ctx context.Context) (r Readiness) {
+	ctx, span := startSpan(ctx, "Readiness")
+	defer func() { endSpan(span, nil) }()
+
+	r.CheckedAt = time.Now()
+	r.RowCounts = make(map[string]int64)
+	run := func(name string, check func() error) {
+		start := time.Now()
+		err := check()
+		if err != nil {
+			s.logger.WarnContext(ctx, "Readiness check failed", "check", name, "error", err)
+		}
+		r.Checks = append(r.Checks, Check{Name: name, Err: err, Duration: time.Since(start)})
+	}
+
+	run(CheckSchema, func() error { return s.checkSchema(ctx) })
+	run(CheckRowCounts, func() error { return s.countRows(ctx, r.RowCounts) })
+	run(CheckFullText, func() error { return s.runCanary(ctx, fullTextCanary) })
+	run(CheckJaroWinkler, func() error { return s.runCanary(ctx, jaroWinklerCanary) })
+	run(CheckVersion, func() (err error) {
+		r.DatasetVersion, err = s.DatasetVersion(ctx)
+		return err
+	})
+
+	r.Ready = true
+	for _, c := range r.Checks {
+		r.Ready = r.Ready && c.Err == nil
+	}
+	return r
+}
+
+// checkSchema fails when the regions table lacks one of regionColumns.
+func (s *Service) checkSchema(ctx context.Context) error {
+	rows, err := s.query(ctx, "Readiness", "SELECT column_name FROM information_schema.columns WHERE table_name = 'regions'")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := make(map[string]bool)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return err
+		}
+		found[column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return fmt.Errorf("table regions does not exist")
+	}
+	var missing []string
+	for _, column := range regionColumns {
+		if !found[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("table regions lacks columns %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// countRows stores the row count of each existing table of countedTables in
+// counts. It fails when the regions table is empty.
+func (s *Service) countRows(ctx context.Context, counts map[string]int64) error {
+	for _, table := range countedTables {
+		if table != "regions" {
+			exists, err := s.tableExists(ctx, table)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				continue
+			}
+		}
+		var count int64
+		if err := s.queryRow(ctx, "Readiness", "SELECT COUNT(*) FROM "+table).Scan(&count); err != nil {
+			return err
+		}
+		counts[table] = count
+	}
+	if counts["regions"] == 0 {
+		return fmt.Errorf("table regions is empty")
+	}
+	return nil
+}
+
+// runCanary runs a counting query and discards its result.
+func (s *Service) runCanary(ctx context.Context, query string) error {
+	var count int64
+	return s.queryRow(ctx, "Readiness", query).Scan(&count)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReadiness(t *testing.T) {
+	db := openSearchDB(t)
+	r := New(db).Readiness(context.Background())
+
+	// The test table has no full-text index, so only that check fails
+	failed := make(map[string]bool)
+	for _, check := range r.Checks {
+		failed[check.Name] = check.Err != nil
+	}
+	want := map[string]bool{CheckSchema: false, CheckRowCounts: false, CheckFullText: true, CheckJaroWinkler: false, CheckVersion: false}
+	for name, wantFailed := range want {
+		if failed[name] != wantFailed {
+			t.Errorf("check %s failed = %v, want %v", name, failed[name], wantFailed)
+		}
+	}
+	if r.Ready {
+		t.Error("expected the service not to be ready without a full-text index")
+	}
+	if r.RowCounts["regions"] != 4 || r.DatasetVersion == "" {
+		t.Errorf("expected 4 regions and a dataset version, got %v and %q", r.RowCounts, r.DatasetVersion)
+	}
+
+	if _, err := db.Exec("ALTER TABLE regions DROP COLUMN full_text"); err != nil {
+		t.Fatalf("failed to drop column: %v", err)
+	}
+	r = New(db).Readiness(context.Background())
+	if r.Checks[0].Name != CheckSchema || r.Checks[0].Err == nil {
+		t.Errorf("expected the schema check to fail without full_text, got %+v", r.Checks[0])
+	}
+}
+
+func TestReadinessCache(t *testing.T) {
+	db := openSearchDB(t)
+	svc := New(db, WithReadinessCache(time.Hour))
+
+	// Failures are not cached, so the service is ready once the index exists
+	if svc.Readiness(context.Background()).Ready {
+		t.Fatal("expected the service not to be ready without a full-text index")
+	}
+	if _, err := db.Exec("CREATE SCHEMA fts_main_regions; CREATE MACRO fts_main_regions.match_bm25(id, query) AS 1.0"); err != nil {
+		t.Fatalf("failed to stub the full-text index: %v", err)
+	}
+	first := svc.Readiness(context.Background())
+	if !first.Ready {
+		t.Fatalf("expected the service to be ready, got %+v", first.Checks)
+	}
+
+	// A successful result is reused until it expires
+	if _, err := db.Exec("DROP MACRO fts_main_regions.match_bm25"); err != nil {
+		t.Fatalf("failed to drop the full-text stub: %v", err)
+	}
+	if r := svc.Readiness(context.Background()); !r.Ready || !r.CheckedAt.Equal(first.CheckedAt) {
+		t.Errorf("expected the cached result, got ready = %v checked at %s", r.Ready, r.CheckedAt)
+	}
+	if New(db).Readiness(context.Background()).Ready {
+		t.Error("expected an uncached service to run the checks again")
+	}
+}
+
+func TestReadinessTimeout(t *testing.T) {
+	db := openSearchDB(t)
+	if _, err := db.Exec("CREATE SCHEMA fts_main_regions; CREATE MACRO fts_main_regions.match_bm25(id, query) AS (SELECT SUM(i) FROM range(10000000000) AS t(i))"); err != nil {
+		t.Fatalf("failed to stub a slow full-text index: %v", err)
+	}
+
+	start := time.Now()
+	r := New(db, WithReadinessTimeout(100*time.Millisecond)).Readiness(context.Background())
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("readiness took %s despite the timeout", elapsed)
+	}
+	if r.Ready {
+		t.Error("expected the service not to be ready when the checks time out")
+	}
+}
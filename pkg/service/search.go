@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Search kinds accepted by SearchPage.
+const (
+	SearchAll         = "all"
+	SearchDistrict    = "district"
+	SearchSubdistrict = "subdistrict"
+	SearchCity        = "city"
+	SearchProvince    = "province"
+	SearchPostalCode  = "postal_code"
+)
+
+// Result limits applied by SearchPage unless set with WithSearchLimits.
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+// DefaultSimilarity is the minimum Jaro-Winkler similarity of a fuzzy match
+// unless set with WithSimilarityThreshold.
+const DefaultSimilarity = 0.8
+
+// SimilarityIslands selects the island search in WithSimilarityThreshold,
+// alongside the search kinds.
+const SimilarityIslands = "islands"
+
+// similarityPlaceholder stands for the similarity threshold of a search kind
+// in its SQL.
+const similarityPlaceholder = "{similarity}"
+
+// SearchOptions controls which page of results a search returns. A zero
+// Limit selects the default limit.
+type SearchOptions struct {
+	Limit  int
+	Offset int
+
+	// Fields selects the region fields to read, from RegionFields, leaving
+	// the others empty. No fields select them all. The id is always read.
+	// Island searches ignore it.
+	Fields []string
+}
+
+// Page is one page of search results together with the total number of matches.
+type Page[T any] struct {
+	Items  []T
+	Total  int
+	Limit  int
+	Offset int
+
+	// Query is the query as searched, after normalizeQuery, and Expansions
+	// lists the queries it was expanded to, such as the prefixed city names.
+	Query      string
+	Expansions []string
+}
+
+// searchQuery describes how one search kind filters and orders regions. The
+// query string is bound once for every placeholder in from, where and orderBy.
+// Ties are broken by id so that pages are stable. The query is matched with
+// each of prefixes when set.
+type searchQuery struct {
+	name     string
+	method   string
+	from     string
+	where    string
+	orderBy  string
+	prefixes []string
+}
+
+// cityPrefixes are prepended to the query of a city search, since city names
+// start with their type.
+var cityPrefixes = []string{"Kota ", "Kabupaten "}
+
+// searchQueries holds the SQL of every search kind.
+var searchQueries = map[string]searchQuery{
+	SearchAll: {
+		name:    "search",
+		method:  "Search",
+		from:    "(SELECT *, fts_main_regions.match_bm25(id, ?) AS score FROM regions)",
+		where:   "score IS NOT NULL",
+		orderBy: "score DESC",
+	},
+	SearchDistrict: {
+		name:    "district search",
+		method:  "SearchByDistrict",
+		from:    "regions",
+		where:   "jaro_winkler_similarity (district, ?) >= {similarity}",
+		orderBy: "jaro_winkler_similarity (district, ?) DESC",
+	},
+	SearchSubdistrict: {
+		name:    "subdistrict search",
+		method:  "SearchBySubdistrict",
+		from:    "regions",
+		where:   "jaro_winkler_similarity (subdistrict, ?) >= {similarity}",
+		orderBy: "jaro_winkler_similarity (subdistrict, ?) DESC",
+	},
+	SearchCity: {
+		name:     "city search",
+		method:   "SearchByCity",
+		from:     "regions",
+		where:    prefixedSimilarity("city", cityPrefixes, " >= {similarity}", " OR "),
+		orderBy:  prefixedSimilarity("city", cityPrefixes, " DESC", ", "),
+		prefixes: cityPrefixes,
+	},
+	SearchProvince: {
+		name:    "province search",
+		method:  "SearchByProvince",
+		from:    "regions",
+		where:   "jaro_winkler_similarity (province, ?) >= {similarity}",
+		orderBy: "jaro_winkler_similarity (province, ?) DESC",
+	},
+	SearchPostalCode: {
+		name:    "postal code search",
+		method:  "SearchByPostalCode",
+		from:    "regions",
+		where:   "postal_code = ?",
+		orderBy: "full_text",
+	},
+}
+
+// prefixedSimilarity returns the Jaro-Winkler similarity of column to the
+// query with each of prefixes, followed by suffix and joined by sep.
+func prefixedSimilarity(column string, prefixes []string, suffix, sep string) string {
+	parts := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		parts[i] = "jaro_winkler_similarity (" + column + ", '" + prefix + "' || ?)" + suffix
+	}
+	return strings.Join(parts, sep)
+}
+
+// normalizeQuery trims a search query and collapses the whitespace inside it.
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// normalize applies the default limit and rejects out-of-range values.
+func (o SearchOptions) normalize(defaultLimit, maxLimit int) (SearchOptions, error) {
+	if o.Limit == 0 {
+		o.Limit = defaultLimit
+	}
+	if o.Limit < 0 || o.Limit > maxLimit {
+		return o, NewFieldError("limit", fmt.Sprintf("limit must be between 1 and %d", maxLimit))
+	}
+	if o.Offset < 0 {
+		return o, NewFieldError("offset", "offset must not be negative")
+	}
+	return o, validateFields(o.Fields)
+}
+
+// SearchPage runs a search of the given kind and returns one page of results
+// with the total number of matches. A postal code search without matches
+// returns ErrCodeNotFound.
+func (s *Service) SearchPage(ctx context.Context, kind, query string, opts SearchOptions) (_ *Page[Region], err error) {
+	ctx, span := startSpan(ctx, "SearchPage", AttrSearchKind.String(kind))
+	defer func() { endSpan(span, err) }()
+
+	q, ok := searchQueries[kind]
+	if !ok {
+		return nil, NewFieldError("kind", fmt.Sprintf("unknown search kind %q", kind))
+	}
+	query = normalizeQuery(query)
+	if query == "" {
+		if kind == SearchPostalCode {
+			return nil, NewFieldError("postal_code", "postal code parameter is required")
+		}
+		return nil, NewFieldError("query", "query parameter is required")
+	}
+	opts, err = opts.normalize(s.defaultLimit, s.maxLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fuzzy searches are full scans, so recent pages are served from memory
+	key := searchKey{kind: kind, query: query, limit: opts.Limit, offset: opts.Offset, fields: strings.Join(opts.Fields, ",")}
+	if s.searchCache != nil {
+		page, ok := s.searchCache.get(key)
+		s.observer.ObserveCache(CacheSearchResults, ok)
+		if ok {
+			span.SetAttributes(AttrResultCount.Int(len(page.Items)), AttrTotal.Int(page.Total))
+			return page.clone(), nil
+		}
+	}
+
+	s.logger.DebugContext(ctx, "Processing "+q.name+" request", "query", query, "limit", opts.Limit, "offset", opts.Offset)
+
+	// Prepare and execute the SQL query, counting every match before the limit
+	q.where = strings.ReplaceAll(q.where, similarityPlaceholder, s.similarity(kind))
+	columns, destinations := selectedColumns(opts.Fields, false)
+	sqlQuery := `
+		SELECT ` + strings.Join(columns, ", ") + `, COUNT(*) OVER () AS total
+		FROM ` + q.from + `
+		WHERE ` + q.where + `
+		ORDER BY ` + q.orderBy + `, id
+		LIMIT ? OFFSET ?
+	`
+	args := queryArgs(query, q.from, q.where, q.orderBy)
+	rows, err := s.query(ctx, q.method, sqlQuery, append(args, opts.Limit, opts.Offset)...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Database query failed", "error", err, "query", query)
+		return nil, WrapError(ErrCodeDatabaseFailure, err, "database query failed")
+	}
+	defer rows.Close()
+
+	// Iterate through the results
+	results, total, err := s.scanRegions(ctx, rows, destinations)
+	if err != nil {
+		return nil, err
+	}
+
+	// A page past the last match carries no total, so count separately
+	if len(results) == 0 && opts.Offset > 0 {
+		countQuery := "SELECT COUNT(*) FROM " + q.from + " WHERE " + q.where
+		if err := s.queryRow(ctx, q.method, countQuery, queryArgs(query, q.from, q.where)...).Scan(&total); err != nil {
+			s.logger.ErrorContext(ctx, "Database query failed", "error", err, "query", query)
+			return nil, WrapError(ErrCodeDatabaseFailure, err, "database query failed")
+		}
+	}
+
+	s.observer.ObserveSearch(q.method, total)
+	span.SetAttributes(AttrResultCount.Int(len(results)), AttrTotal.Int(total))
+
+	if kind == SearchPostalCode && total == 0 {
+		s.logger.InfoContext(ctx, "No results found for postal code", "postalCode", query)
+		return nil, NewError(ErrCodeNotFound, "no regions found for the provided postal code")
+	}
+
+	s.logger.InfoContext(ctx, "Search completed", "kind", kind, "query", query, "results", len(results), "total", total)
+	page := &Page[Region]{Items: results, Total: total, Limit: opts.Limit, Offset: opts.Offset, Query: query}
+	for _, prefix := range q.prefixes {
+		page.Expansions = append(page.Expansions, prefix+query)
+	}
+	if s.searchCache != nil {
+		s.searchCache.add(key, page.clone())
+	}
+	return page, nil
+}
+
+// similarity returns the Jaro-Winkler threshold of a search kind as SQL.
+func (s *Service) similarity(kind string) string {
+	threshold, ok := s.similarities[kind]
+	if !ok {
+		threshold = DefaultSimilarity
+	}
+	return strconv.FormatFloat(threshold, 'f', -1, 64)
+}
+
+// clone returns a copy of p whose items can be modified, such as by
+// WithAttributes, without affecting p.
+func (p *Page[T]) clone() *Page[T] {
+	copied := *p
+	copied.Items = append([]T(nil), p.Items...)
+	return &copied
+}
+
+// queryArgs binds query once for every placeholder in the SQL fragments.
+func queryArgs(query string, fragments ...string) []interface{} {
+	var args []interface{}
+	for _, fragment := range fragments {
+		for i := 0; i < strings.Count(fragment, "?"); i++ {
+			args = append(args, query)
+		}
+	}
+	return args
+}
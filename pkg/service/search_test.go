@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+// openSearchDB returns an in-memory database with a small regions table.
+func openSearchDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE regions (id VARCHAR, subdistrict VARCHAR, district VARCHAR, city VARCHAR,
+			province VARCHAR, postal_code VARCHAR, full_text VARCHAR);
+		INSERT INTO regions VALUES
+			('32.73.01.1001', 'Sarijadi', 'Sukasari', 'Kota Bandung', 'Jawa Barat', '40151', ''),
+			('32.73.01.1002', 'Sukarasa', 'Sukasari', 'Kota Bandung', 'Jawa Barat', NULL, ''),
+			('32.73.02.1001', 'Hegarmanah', 'Cidadap', 'Kota Bandung', 'Jawa Barat', '40141', ''),
+			('31.71.01.1001', 'Gambir', 'Gambir', 'Kota Adm. Jakarta Pusat', 'DKI Jakarta', '10110', '');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create regions: %v", err)
+	}
+	return db
+}
+
+func TestSearchPage(t *testing.T) {
+	svc := New(openSearchDB(t))
+
+	page, err := svc.SearchPage(context.Background(), SearchProvince, "Jawa Barat", SearchOptions{Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf("SearchPage returned error: %v", err)
+	}
+	if page.Total != 3 || page.Limit != 2 || page.Offset != 1 {
+		t.Errorf("unexpected page metadata: total=%d limit=%d offset=%d", page.Total, page.Limit, page.Offset)
+	}
+	if len(page.Items) != 2 || page.Items[0].ID != "32.73.01.1002" || page.Items[0].PostalCode != "" {
+		t.Errorf("unexpected page items: %+v", page.Items)
+	}
+
+	// A page past the last match still reports the total
+	page, err = svc.SearchPage(context.Background(), SearchProvince, "Jawa Barat", SearchOptions{Offset: 10})
+	if err != nil {
+		t.Fatalf("SearchPage returned error: %v", err)
+	}
+	if len(page.Items) != 0 || page.Total != 3 {
+		t.Errorf("expected an empty page with total 3, got %d items and total %d", len(page.Items), page.Total)
+	}
+
+	// Queries are normalized, and city searches expanded with the city prefixes
+	page, err = svc.SearchPage(context.Background(), SearchCity, "  Bandung ", SearchOptions{})
+	if err != nil || page.Total != 3 || page.Query != "Bandung" || !reflect.DeepEqual(page.Expansions, []string{"Kota Bandung", "Kabupaten Bandung"}) {
+		t.Errorf("unexpected normalized city search %+v, %v", page, err)
+	}
+	if _, err := svc.SearchPage(context.Background(), SearchCity, "   ", SearchOptions{}); !IsError(err, ErrCodeInvalidInput) {
+		t.Errorf("expected invalid input error for a blank query, got %v", err)
+	}
+
+	if _, err := svc.SearchPage(context.Background(), SearchPostalCode, "99999", SearchOptions{}); !IsError(err, ErrCodeNotFound) {
+		t.Errorf("expected not found error for an unknown postal code, got %v", err)
+	}
+	if _, err := svc.SearchPage(context.Background(), SearchDistrict, "Sukasari", SearchOptions{Limit: MaxLimit + 1}); !IsError(err, ErrCodeInvalidInput) {
+		t.Errorf("expected invalid input error for a limit above the maximum, got %v", err)
+	}
+	regions, err := svc.SearchByPostalCode("10110")
+	if err != nil || len(regions) != 1 || regions[0].Subdistrict != "Gambir" {
+		t.Errorf("SearchByPostalCode returned %v, %v", regions, err)
+	}
+}
+
+// cacheObserver counts the cache lookups reported to it.
+type cacheObserver struct {
+	nopObserver
+	hits, misses int
+}
+
+func (o *cacheObserver) ObserveCache(_ string, hit bool) {
+	if hit {
+		o.hits++
+	} else {
+		o.misses++
+	}
+}
+
+func TestSearchPageOptions(t *testing.T) {
+	db := openSearchDB(t)
+	ctx := context.Background()
+
+	// "Sukasar" is similar enough to "Sukasari" at the default threshold only
+	if page, err := New(db).SearchPage(ctx, SearchDistrict, "Sukasar", SearchOptions{}); err != nil || page.Total != 2 {
+		t.Errorf("expected 2 fuzzy matches, got %v, %v", page, err)
+	}
+	strict := New(db, WithSimilarityThreshold(SearchDistrict, 0.99))
+	if page, err := strict.SearchPage(ctx, SearchDistrict, "Sukasar", SearchOptions{}); err != nil || page.Total != 0 {
+		t.Errorf("expected no matches with a strict threshold, got %v, %v", page, err)
+	}
+
+	limited := New(db, WithSearchLimits(1, 2))
+	page, err := limited.SearchPage(ctx, SearchProvince, "Jawa Barat", SearchOptions{})
+	if err != nil || page.Limit != 1 || len(page.Items) != 1 {
+		t.Errorf("expected the configured default limit, got %v, %v", page, err)
+	}
+	if _, err := limited.SearchPage(ctx, SearchProvince, "Jawa Barat", SearchOptions{Limit: 3}); !IsError(err, ErrCodeInvalidInput) {
+		t.Errorf("expected invalid input error above the configured maximum, got %v", err)
+	}
+
+	observer := &cacheObserver{}
+	cached := New(db, WithSearchCache(1), WithObserver(observer))
+	for _, query := range []string{"Jawa Barat", "Jawa Barat", "DKI Jakarta", "Jawa Barat"} {
+		page, err := cached.SearchPage(ctx, SearchProvince, query, SearchOptions{})
+		if err != nil || len(page.Items) == 0 {
+			t.Fatalf("SearchPage(%q) returned %v, %v", query, page, err)
+		}
+		// Changes to a returned page must not reach the cache
+		page.Items[0].ID = "changed"
+	}
+	if observer.hits != 1 || observer.misses != 3 {
+		t.Errorf("expected 1 hit and 3 misses, got %d and %d", observer.hits, observer.misses)
+	}
+	page, err = cached.SearchPage(ctx, SearchProvince, "Jawa Barat", SearchOptions{})
+	if err != nil || page.Items[0].ID == "changed" {
+		t.Errorf("expected an unmodified cached page, got %v, %v", page, err)
+	}
+}
+
+func TestSearchPageFields(t *testing.T) {
+	svc := New(openSearchDB(t))
+	ctx := context.Background()
+
+	page, err := svc.SearchPage(ctx, SearchProvince, "Jawa Barat", SearchOptions{Limit: 1, Fields: []string{FieldCity, FieldPostalCode}})
+	if err != nil {
+		t.Fatalf("SearchPage returned error: %v", err)
+	}
+	// The id is read even when not selected
+	want := Region{ID: "32.73.01.1001", City: "Kota Bandung", PostalCode: "40151"}
+	if page.Total != 3 || len(page.Items) != 1 || !reflect.DeepEqual(page.Items[0], want) {
+		t.Errorf("expected %+v, got %+v", want, page.Items)
+	}
+
+	if _, err := svc.SearchPage(ctx, SearchProvince, "Jawa Barat", SearchOptions{Fields: []string{"name"}}); !IsError(err, ErrCodeInvalidInput) {
+		t.Errorf("expected invalid input error for an unknown field, got %v", err)
+	}
+
+	region, err := svc.GetByCodeFields(ctx, "32.73.01", []string{FieldDistrict})
+	if err != nil || !reflect.DeepEqual(*region, Region{ID: "32.73.01", District: "Sukasari"}) {
+		t.Errorf("GetByCodeFields returned %+v, %v", region, err)
+	}
+	region, err = svc.GetByCode(ctx, "32.73.01")
+	if err != nil || region.City != "Kota Bandung" || region.FullText != "jawa barat kota bandung sukasari" {
+		t.Errorf("GetByCode returned %+v, %v", region, err)
+	}
+}
@@ -4,8 +4,11 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"log/slog"
+	"sync"
+	"time"
 )
 
 // Region represents a region in Indonesia with all its administrative divisions.
@@ -17,280 +20,196 @@ type Region struct {
 	Province    string `json:"province"`
 	PostalCode  string `json:"postal_code"`
 	FullText    string `json:"full_text"`
+
+	// Coordinates, area and population, only set on province and city
+	// results when the database includes region stats
+	Latitude   *float64 `json:"latitude,omitempty"`
+	Longitude  *float64 `json:"longitude,omitempty"`
+	AreaKm2    *float64 `json:"area_km2,omitempty"`
+	Population *int64   `json:"population,omitempty"`
+
+	// Attributes holds the attribute sets requested through WithAttributes, keyed by set name.
+	Attributes map[string]map[string]interface{} `json:"attributes,omitempty"`
 }
 
 // Service encapsulates the business logic for region searches.
 type Service struct {
 	db *sql.DB
-}
 
-// New creates a new Service instance with the provided database connection.
-func New(db *sql.DB) *Service {
-	return &Service{
-		db: db,
-	}
-}
+	versionMu sync.Mutex
+	version   string
 
-// Search performs a general search across all regions based on the provided query.
-func (s *Service) Search(query string) ([]Region, error) {
-	if query == "" {
-		return nil, NewError(ErrCodeInvalidInput, "query parameter is required")
-	}
+	attributesMu sync.Mutex
+	attributes   map[string]attributeSet
 
-	slog.Info("Processing search request", "query", query)
+	statsMu sync.Mutex
+	stats   map[string]RegionStats
 
-	// Prepare and execute the SQL query for Full-Text Search
-	sqlQuery := `
-		SELECT id, subdistrict, district, city, province, postal_code, full_text, score
-		FROM (
-			SELECT *, fts_main_regions.match_bm25(id, ?) AS score
-			FROM regions
-		)
-		WHERE score IS NOT NULL
-		ORDER BY score DESC
-		LIMIT 10;
-	`
+	readinessMu      sync.Mutex
+	readiness        Readiness
+	readinessDone    time.Time
+	readinessTTL     time.Duration
+	readinessTimeout time.Duration
 
-	rows, err := s.db.Query(sqlQuery, query)
-	if err != nil {
-		slog.Error("Database query failed", "error", err, "query", query)
-		return nil, NewErrorf(ErrCodeDatabaseFailure, "database query failed: %v", err)
-	}
-	defer rows.Close()
+	defaultLimit int
+	maxLimit     int
+	similarities map[string]float64
+	searchCache  *lruCache[searchKey, *Page[Region]]
 
-	// Iterate through the results
-	results, err := s.scanRegions(rows)
-	if err != nil {
-		return nil, err
-	}
-
-	slog.Info("Search completed", "query", query, "results", len(results))
-	return results, nil
+	observer Observer
+	logger   *slog.Logger
 }
 
-// SearchByDistrict searches for regions by district name.
-func (s *Service) SearchByDistrict(query string) ([]Region, error) {
-	if query == "" {
-		return nil, NewError(ErrCodeInvalidInput, "query parameter is required")
-	}
-
-	slog.Info("Processing district search request", "query", query)
-
-	// Prepare and execute the SQL query
-	sqlQuery := `
-		SELECT id, subdistrict, district, city, province, postal_code, full_text
-		FROM regions
-		WHERE jaro_winkler_similarity (district, ?) >= 0.8
-		ORDER BY jaro_winkler_similarity (district, ?) DESC
-		LIMIT 10
-	`
-
-	rows, err := s.db.Query(sqlQuery, query, query)
-	if err != nil {
-		slog.Error("Database query failed", "error", err, "query", query)
-		return nil, NewErrorf(ErrCodeDatabaseFailure, "database query failed: %v", err)
-	}
-	defer rows.Close()
+// Option configures optional behaviour of a Service.
+type Option func(*Service)
 
-	// Iterate through the results
-	results, err := s.scanRegions(rows)
-	if err != nil {
-		return nil, err
+// WithObserver reports query durations, search totals and cache lookups to o.
+func WithObserver(o Observer) Option {
+	return func(s *Service) {
+		s.observer = o
 	}
-
-	slog.Info("District search completed", "query", query, "results", len(results))
-	return results, nil
 }
 
-// SearchBySubdistrict searches for regions by subdistrict name.
-func (s *Service) SearchBySubdistrict(query string) ([]Region, error) {
-	if query == "" {
-		return nil, NewError(ErrCodeInvalidInput, "query parameter is required")
+// WithLogger logs the requests handled by the service to logger instead of
+// the default slog logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Service) {
+		s.logger = logger
 	}
+}
 
-	slog.Info("Processing subdistrict search request", "query", query)
-
-	// Prepare and execute the SQL query
-	sqlQuery := `
-		SELECT id, subdistrict, district, city, province, postal_code, full_text
-		FROM regions
-		WHERE jaro_winkler_similarity (subdistrict, ?) >= 0.8
-		ORDER BY jaro_winkler_similarity (subdistrict, ?) DESC
-		LIMIT 10
-	`
-
-	rows, err := s.db.Query(sqlQuery, query, query)
-	if err != nil {
-		slog.Error("Database query failed", "error", err, "query", query)
-		return nil, NewErrorf(ErrCodeDatabaseFailure, "database query failed: %v", err)
+// WithSearchLimits sets the number of results returned when a search does
+// not set a limit, and the largest limit a search may set.
+func WithSearchLimits(defaultLimit, maxLimit int) Option {
+	return func(s *Service) {
+		s.defaultLimit = defaultLimit
+		s.maxLimit = maxLimit
 	}
-	defer rows.Close()
+}
 
-	// Iterate through the results
-	results, err := s.scanRegions(rows)
-	if err != nil {
-		return nil, err
+// WithSimilarityThreshold sets the minimum Jaro-Winkler similarity of the
+// fuzzy matches of a search kind, or of the island search when kind is
+// SimilarityIslands.
+func WithSimilarityThreshold(kind string, threshold float64) Option {
+	return func(s *Service) {
+		s.similarities[kind] = threshold
 	}
-
-	slog.Info("Subdistrict search completed", "query", query, "results", len(results))
-	return results, nil
 }
 
-// SearchByCity searches for regions by city name.
-func (s *Service) SearchByCity(query string) ([]Region, error) {
-	if query == "" {
-		return nil, NewError(ErrCodeInvalidInput, "query parameter is required")
+// WithSearchCache keeps the last size search result pages in memory. The
+// dataset is read-only, so cached pages never go stale. A size of zero
+// disables the cache.
+func WithSearchCache(size int) Option {
+	return func(s *Service) {
+		s.searchCache = nil
+		if size > 0 {
+			s.searchCache = newLRUCache[searchKey, *Page[Region]](size)
+		}
 	}
+}
 
-	slog.Info("Processing city search request", "query", query)
-
-	// Prepare and execute the SQL query
-	sqlQuery := `
-		SELECT id, subdistrict, district, city, province, postal_code, full_text
-		FROM regions
-		WHERE
-		    jaro_winkler_similarity (city, 'Kota ' || ?) >= 0.8
-			OR jaro_winkler_similarity (city, 'Kabupaten ' || ?) >= 0.8
-		ORDER BY jaro_winkler_similarity (city, 'Kota ' || ?) DESC, jaro_winkler_similarity (city, 'Kabupaten ' || ?) DESC
-		LIMIT 10
-	`
-
-	rows, err := s.db.Query(sqlQuery, query, query, query, query)
-	if err != nil {
-		slog.Error("Database query failed", "error", err, "query", query)
-		return nil, NewErrorf(ErrCodeDatabaseFailure, "database query failed: %v", err)
+// WithReadinessCache reuses a successful Readiness result for ttl, so that
+// frequent probes do not run the canary queries each time. Failed results
+// are never reused. A ttl of zero disables the cache.
+func WithReadinessCache(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.readinessTTL = ttl
 	}
-	defer rows.Close()
+}
 
-	// Iterate through the results
-	results, err := s.scanRegions(rows)
-	if err != nil {
-		return nil, err
+// WithReadinessTimeout fails the Readiness checks that have not completed
+// within timeout, so that a slow database does not hold every probe. It
+// should be shorter than the probe timeout. A timeout of zero disables it.
+func WithReadinessTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		s.readinessTimeout = timeout
 	}
+}
 
-	slog.Info("City search completed", "query", query, "results", len(results))
-	return results, nil
+// New creates a new Service instance with the provided database connection.
+func New(db *sql.DB, opts ...Option) *Service {
+	s := &Service{
+		db:           db,
+		defaultLimit: DefaultLimit,
+		maxLimit:     MaxLimit,
+		similarities: make(map[string]float64),
+		observer:     nopObserver{},
+		logger:       slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// SearchByProvince searches for regions by province name.
-func (s *Service) SearchByProvince(query string) ([]Region, error) {
-	if query == "" {
-		return nil, NewError(ErrCodeInvalidInput, "query parameter is required")
+// Ping checks that the database connection is alive.
+func (s *Service) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return WrapError(ErrCodeDatabaseFailure, err, "database connection failed")
 	}
+	return nil
+}
 
-	slog.Info("Processing province search request", "query", query)
+// Search performs a general search across all regions based on the provided query.
+func (s *Service) Search(query string) ([]Region, error) {
+	return s.searchItems(SearchAll, query)
+}
 
-	// Prepare and execute the SQL query
-	sqlQuery := `
-		SELECT id, subdistrict, district, city, province, postal_code, full_text
-		FROM regions
-		WHERE jaro_winkler_similarity (province, ?) >= 0.8
-		ORDER BY jaro_winkler_similarity (province, ?) DESC
-		LIMIT 10
-	`
+// SearchByDistrict searches for regions by district name.
+func (s *Service) SearchByDistrict(query string) ([]Region, error) {
+	return s.searchItems(SearchDistrict, query)
+}
 
-	rows, err := s.db.Query(sqlQuery, query, query)
-	if err != nil {
-		slog.Error("Database query failed", "error", err, "query", query)
-		return nil, NewErrorf(ErrCodeDatabaseFailure, "database query failed: %v", err)
-	}
-	defer rows.Close()
+// SearchBySubdistrict searches for regions by subdistrict name.
+func (s *Service) SearchBySubdistrict(query string) ([]Region, error) {
+	return s.searchItems(SearchSubdistrict, query)
+}
 
-	// Iterate through the results
-	results, err := s.scanRegions(rows)
-	if err != nil {
-		return nil, err
-	}
+// SearchByCity searches for regions by city name.
+func (s *Service) SearchByCity(query string) ([]Region, error) {
+	return s.searchItems(SearchCity, query)
+}
 
-	slog.Info("Province search completed", "query", query, "results", len(results))
-	return results, nil
+// SearchByProvince searches for regions by province name.
+func (s *Service) SearchByProvince(query string) ([]Region, error) {
+	return s.searchItems(SearchProvince, query)
 }
 
 // SearchByPostalCode searches for regions by postal code.
 func (s *Service) SearchByPostalCode(postalCode string) ([]Region, error) {
-	if postalCode == "" {
-		return nil, NewError(ErrCodeInvalidInput, "postal code parameter is required")
-	}
-
-	
-
-	slog.Info("Processing postal code search request", "postalCode", postalCode)
-
-	// Prepare and execute the SQL query
-	sqlQuery := `
-		SELECT id, subdistrict, district, city, province, postal_code, full_text
-		FROM regions
-		WHERE postal_code = ?
-		ORDER BY full_text
-		LIMIT 10
-	`
-
-	rows, err := s.db.Query(sqlQuery, postalCode)
-	if err != nil {
-		slog.Error("Database query failed", "error", err, "postalCode", postalCode)
-		return nil, NewErrorf(ErrCodeDatabaseFailure, "database query failed: %v", err)
-	}
-	defer rows.Close()
+	return s.searchItems(SearchPostalCode, postalCode)
+}
 
-	// Iterate through the results
-	results, err := s.scanRegions(rows)
+// searchItems returns the first page of results with the default limit.
+func (s *Service) searchItems(kind, query string) ([]Region, error) {
+	page, err := s.SearchPage(context.Background(), kind, query, SearchOptions{})
 	if err != nil {
 		return nil, err
 	}
-
-	if len(results) == 0 {
-		slog.Info("No results found for postal code", "postalCode", postalCode)
-		return nil, NewError(ErrCodeNotFound, "no regions found for the provided postal code")
-	}
-
-	slog.Info("Postal code search completed", "postalCode", postalCode, "results", len(results))
-	return results, nil
+	return page.Items, nil
 }
 
-// scanRegions iterates through the SQL rows and converts them to Region structs.
-func (s *Service) scanRegions(rows *sql.Rows) ([]Region, error) {
+// scanRegions iterates through the SQL rows and converts them to Region
+// structs, scanning the columns selected with selectedColumns into
+// destinations. The rows carry the total number of matches in a last column.
+func (s *Service) scanRegions(ctx context.Context, rows *sql.Rows, destinations func(*Region) []interface{}) ([]Region, int, error) {
 	var results []Region
+	total := 0
 	for rows.Next() {
 		var region Region
-		var score sql.NullFloat64 // Use sql.NullFloat64 for the score
-
-		// Check the column names to determine which columns to scan
-		cols, err := rows.Columns()
+		err := rows.Scan(append(destinations(&region), &total)...)
 		if err != nil {
-			return nil, NewErrorf(ErrCodeDatabaseFailure, "failed to get columns: %v", err)
-		}
-
-		// Prepare the scan arguments based on the available columns
-		scanArgs := []interface{}{
-			&region.ID,
-			&region.Subdistrict,
-			&region.District,
-			&region.City,
-			&region.Province,
-			&region.PostalCode,
-			&region.FullText,
-		}
-
-		// If the score column is present, add it to the scan arguments
-		if len(cols) > 7 {
-			scanArgs = append(scanArgs, &score)
-		}
-
-		err = rows.Scan(scanArgs...)
-		if err != nil {
-			slog.Error("Failed to scan row", "error", err)
-			return nil, NewErrorf(ErrCodeDatabaseFailure, "failed to scan row: %v", err)
+			s.logger.ErrorContext(ctx, "Failed to scan row", "error", err)
+			return nil, 0, WrapError(ErrCodeDatabaseFailure, err, "failed to scan row")
 		}
 		results = append(results, region)
 	}
 
 	// Check for errors during iteration
 	if err := rows.Err(); err != nil {
-		slog.Error("Error iterating rows", "error", err)
-		return nil, NewErrorf(ErrCodeDatabaseFailure, "error iterating rows: %v", err)
+		s.logger.ErrorContext(ctx, "Error iterating rows", "error", err)
+		return nil, 0, WrapError(ErrCodeDatabaseFailure, err, "error iterating rows")
 	}
 
-	return results, nil
+	return results, total, nil
 }
-
-
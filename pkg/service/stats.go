@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ilmimris/wilayah-indonesia/pkg/schema"
+)
+
+// RegionStatsTable holds the coordinates, area, population and boundaries of
+// provinces and cities, written by the ingestor when the upstream dumps are given.
+const RegionStatsTable = schema.RegionStatsTable
+
+// RegionStats describes the geography and population of a province or city.
+type RegionStats struct {
+	Code       string   `json:"code"`
+	Capital    string   `json:"capital,omitempty"`
+	Latitude   *float64 `json:"latitude"`
+	Longitude  *float64 `json:"longitude"`
+	Elevation  *float64 `json:"elevation"`
+	Timezone   *int     `json:"timezone"`
+	AreaKm2    *float64 `json:"area_km2"`
+	Population *int64   `json:"population"`
+
+	// Boundary is the upstream boundary path, only returned on request.
+	Boundary json.RawMessage `json:"boundary,omitempty"`
+}
+
+// Stats returns the statistics of a province or city. The boundary path is
+// only included when withBoundary is set, as it can be large.
+func (s *Service) Stats(ctx context.Context, code string, withBoundary bool) (_ *RegionStats, err error) {
+	ctx, span := startSpan(ctx, "Stats", AttrCode.String(code))
+	defer func() { endSpan(span, err) }()
+
+	level := LevelOfCode(code)
+	if level == "" {
+		return nil, NewFieldError("code", fmt.Sprintf("invalid region code %q", code))
+	}
+
+	s.logger.DebugContext(ctx, "Processing stats request", "code", code, "boundary", withBoundary)
+
+	stats, err := s.regionStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	st, ok := stats[code]
+	if !ok {
+		s.logger.InfoContext(ctx, "No stats found for code", "code", code)
+		return nil, NewError(ErrCodeNotFound, "no statistics available for the provided code")
+	}
+
+	if withBoundary {
+		var boundary sql.NullString
+		err := s.queryRow(ctx, "Stats", "SELECT boundary FROM "+RegionStatsTable+" WHERE code = ?", code).Scan(&boundary)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Database query failed", "error", err, "code", code)
+			return nil, WrapError(ErrCodeDatabaseFailure, err, "database query failed")
+		}
+		if boundary.Valid && boundary.String != "" {
+			if json.Valid([]byte(boundary.String)) {
+				st.Boundary = json.RawMessage(boundary.String)
+			} else {
+				// Not a JSON path, so return it verbatim as a string
+				st.Boundary, _ = json.Marshal(boundary.String)
+			}
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Stats request completed", "code", code)
+	return &st, nil
+}
+
+// withStats copies the coordinates, area and population of a province or city onto region.
+func (s *Service) withStats(ctx context.Context, region *Region) error {
+	stats, err := s.regionStats(ctx)
+	if err != nil {
+		return err
+	}
+	if st, ok := stats[region.ID]; ok {
+		region.Latitude = st.Latitude
+		region.Longitude = st.Longitude
+		region.AreaKm2 = st.AreaKm2
+		region.Population = st.Population
+	}
+	return nil
+}
+
+// regionStats returns the stats of every province and city without their
+// boundaries, keyed by code. The table is small and the database is opened
+// read-only, so it is read once and cached. Databases built without the
+// stats dumps yield an empty map.
+func (s *Service) regionStats(ctx context.Context) (map[string]RegionStats, error) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	s.observer.ObserveCache(CacheRegionStats, s.stats != nil)
+	if s.stats != nil {
+		return s.stats, nil
+	}
+
+	stats := make(map[string]RegionStats)
+	exists, err := s.tableExists(ctx, RegionStatsTable)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		s.stats = stats
+		return stats, nil
+	}
+
+	rows, err := s.query(ctx, "regionStats", `
+		SELECT code, COALESCE(capital, ''), latitude, longitude, elevation, timezone, area_km2, population
+		FROM `+RegionStatsTable)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Database query failed", "error", err)
+		return nil, WrapError(ErrCodeDatabaseFailure, err, "database query failed")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var st RegionStats
+		var lat, lng, elv, area sql.NullFloat64
+		var tz sql.NullInt32
+		var population sql.NullInt64
+		if err := rows.Scan(&st.Code, &st.Capital, &lat, &lng, &elv, &tz, &area, &population); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to scan row", "error", err)
+			return nil, WrapError(ErrCodeDatabaseFailure, err, "failed to scan row")
+		}
+		st.Latitude = nullFloat(lat)
+		st.Longitude = nullFloat(lng)
+		st.Elevation = nullFloat(elv)
+		st.AreaKm2 = nullFloat(area)
+		if tz.Valid {
+			v := int(tz.Int32)
+			st.Timezone = &v
+		}
+		if population.Valid {
+			st.Population = &population.Int64
+		}
+		stats[st.Code] = st
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "Error iterating rows", "error", err)
+		return nil, WrapError(ErrCodeDatabaseFailure, err, "error iterating rows")
+	}
+
+	s.stats = stats
+	return stats, nil
+}
+
+// nullFloat converts a nullable float to a pointer, nil when NULL.
+func nullFloat(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name of the spans started by the service.
+const TracerName = "github.com/ilmimris/wilayah-indonesia/pkg/service"
+
+// Span attributes set by the service.
+const (
+	AttrSearchKind  = attribute.Key("wilayah.search.kind")
+	AttrCode        = attribute.Key("wilayah.code")
+	AttrLevel       = attribute.Key("wilayah.level")
+	AttrResultCount = attribute.Key("wilayah.result.count")
+	AttrTotal       = attribute.Key("wilayah.result.total")
+)
+
+// tracer starts spans through the global tracer provider, so spans are only
+// recorded once the application installs one.
+var tracer = otel.Tracer(TracerName)
+
+// startSpan starts the span of a Service method.
+func startSpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "Service."+method, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the error returned by a Service method and ends its span.
+// Only database failures mark the span as failed; invalid input and missing
+// regions are answers rather than faults.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !IsError(err, ErrCodeInvalidInput) && !IsError(err, ErrCodeNotFound) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// startQuerySpan starts the client span of a DuckDB query run on behalf of method.
+func startQuerySpan(ctx context.Context, method, sqlQuery string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "duckdb.query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNameKey.String("duckdb"),
+			semconv.DBOperationName(method),
+			semconv.DBQueryText(sqlQuery),
+		),
+	)
+}
@@ -0,0 +1,748 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: wilayah/v1/wilayah.proto
+
+package wilayahv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Region is a region with all its administrative divisions. Regions above
+// subdistrict level leave the lower-level names empty.
+type Region struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Subdistrict string                 `protobuf:"bytes,2,opt,name=subdistrict,proto3" json:"subdistrict,omitempty"`
+	District    string                 `protobuf:"bytes,3,opt,name=district,proto3" json:"district,omitempty"`
+	City        string                 `protobuf:"bytes,4,opt,name=city,proto3" json:"city,omitempty"`
+	Province    string                 `protobuf:"bytes,5,opt,name=province,proto3" json:"province,omitempty"`
+	PostalCode  string                 `protobuf:"bytes,6,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	FullText    string                 `protobuf:"bytes,7,opt,name=full_text,json=fullText,proto3" json:"full_text,omitempty"`
+	// Coordinates, area and population, only set on provinces and cities when
+	// the database includes region stats.
+	Latitude      *float64 `protobuf:"fixed64,8,opt,name=latitude,proto3,oneof" json:"latitude,omitempty"`
+	Longitude     *float64 `protobuf:"fixed64,9,opt,name=longitude,proto3,oneof" json:"longitude,omitempty"`
+	AreaKm2       *float64 `protobuf:"fixed64,10,opt,name=area_km2,json=areaKm2,proto3,oneof" json:"area_km2,omitempty"`
+	Population    *int64   `protobuf:"varint,11,opt,name=population,proto3,oneof" json:"population,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Region) Reset() {
+	*x = Region{}
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Region) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Region) ProtoMessage() {}
+
+func (x *Region) ProtoReflect() protoreflect.Message {
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Region.ProtoReflect.Descriptor instead.
+func (*Region) Descriptor() ([]byte, []int) {
+	return file_wilayah_v1_wilayah_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Region) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Region) GetSubdistrict() string {
+	if x != nil {
+		return x.Subdistrict
+	}
+	return ""
+}
+
+func (x *Region) GetDistrict() string {
+	if x != nil {
+		return x.District
+	}
+	return ""
+}
+
+func (x *Region) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Region) GetProvince() string {
+	if x != nil {
+		return x.Province
+	}
+	return ""
+}
+
+func (x *Region) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *Region) GetFullText() string {
+	if x != nil {
+		return x.FullText
+	}
+	return ""
+}
+
+func (x *Region) GetLatitude() float64 {
+	if x != nil && x.Latitude != nil {
+		return *x.Latitude
+	}
+	return 0
+}
+
+func (x *Region) GetLongitude() float64 {
+	if x != nil && x.Longitude != nil {
+		return *x.Longitude
+	}
+	return 0
+}
+
+func (x *Region) GetAreaKm2() float64 {
+	if x != nil && x.AreaKm2 != nil {
+		return *x.AreaKm2
+	}
+	return 0
+}
+
+func (x *Region) GetPopulation() int64 {
+	if x != nil && x.Population != nil {
+		return *x.Population
+	}
+	return 0
+}
+
+type SearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The search query. Required.
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// The maximum number of results, 10 when unset and at most 100.
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// The number of results to skip.
+	Offset        int32 `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_wilayah_v1_wilayah_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type SearchPostalCodeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The five-digit postal code. Required.
+	PostalCode string `protobuf:"bytes,1,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	// The maximum number of results, 10 when unset and at most 100.
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// The number of results to skip.
+	Offset        int32 `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchPostalCodeRequest) Reset() {
+	*x = SearchPostalCodeRequest{}
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchPostalCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchPostalCodeRequest) ProtoMessage() {}
+
+func (x *SearchPostalCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchPostalCodeRequest.ProtoReflect.Descriptor instead.
+func (*SearchPostalCodeRequest) Descriptor() ([]byte, []int) {
+	return file_wilayah_v1_wilayah_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SearchPostalCodeRequest) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *SearchPostalCodeRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchPostalCodeRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type SearchResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Regions []*Region              `protobuf:"bytes,1,rep,name=regions,proto3" json:"regions,omitempty"`
+	// The total number of matches, regardless of limit and offset.
+	Total         int32 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Limit         int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32 `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_wilayah_v1_wilayah_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SearchResponse) GetRegions() []*Region {
+	if x != nil {
+		return x.Regions
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SearchResponse) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchResponse) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type GetRegionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A Kemendagri code such as 32, 32.73, 32.73.01 or 32.73.01.1001.
+	Code          string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegionRequest) Reset() {
+	*x = GetRegionRequest{}
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegionRequest) ProtoMessage() {}
+
+func (x *GetRegionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegionRequest.ProtoReflect.Descriptor instead.
+func (*GetRegionRequest) Descriptor() ([]byte, []int) {
+	return file_wilayah_v1_wilayah_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetRegionRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+// Error describes a failed lookup within a stream.
+type Error struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The service error code, such as NOT_FOUND or INVALID_INPUT.
+	Code          string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_wilayah_v1_wilayah_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type LookupRegionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The requested code.
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*LookupRegionResponse_Region
+	//	*LookupRegionResponse_Error
+	Result        isLookupRegionResponse_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupRegionResponse) Reset() {
+	*x = LookupRegionResponse{}
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupRegionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupRegionResponse) ProtoMessage() {}
+
+func (x *LookupRegionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupRegionResponse.ProtoReflect.Descriptor instead.
+func (*LookupRegionResponse) Descriptor() ([]byte, []int) {
+	return file_wilayah_v1_wilayah_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LookupRegionResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *LookupRegionResponse) GetResult() isLookupRegionResponse_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *LookupRegionResponse) GetRegion() *Region {
+	if x != nil {
+		if x, ok := x.Result.(*LookupRegionResponse_Region); ok {
+			return x.Region
+		}
+	}
+	return nil
+}
+
+func (x *LookupRegionResponse) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Result.(*LookupRegionResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isLookupRegionResponse_Result interface {
+	isLookupRegionResponse_Result()
+}
+
+type LookupRegionResponse_Region struct {
+	Region *Region `protobuf:"bytes,2,opt,name=region,proto3,oneof"`
+}
+
+type LookupRegionResponse_Error struct {
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*LookupRegionResponse_Region) isLookupRegionResponse_Result() {}
+
+func (*LookupRegionResponse_Error) isLookupRegionResponse_Result() {}
+
+type LookupPostalCodeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The requested postal code.
+	PostalCode string    `protobuf:"bytes,1,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	Regions    []*Region `protobuf:"bytes,2,rep,name=regions,proto3" json:"regions,omitempty"`
+	// Set when the lookup failed, for instance because the postal code is unknown.
+	Error         *Error `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupPostalCodeResponse) Reset() {
+	*x = LookupPostalCodeResponse{}
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupPostalCodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupPostalCodeResponse) ProtoMessage() {}
+
+func (x *LookupPostalCodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wilayah_v1_wilayah_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupPostalCodeResponse.ProtoReflect.Descriptor instead.
+func (*LookupPostalCodeResponse) Descriptor() ([]byte, []int) {
+	return file_wilayah_v1_wilayah_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LookupPostalCodeResponse) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *LookupPostalCodeResponse) GetRegions() []*Region {
+	if x != nil {
+		return x.Regions
+	}
+	return nil
+}
+
+func (x *LookupPostalCodeResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+var File_wilayah_v1_wilayah_proto protoreflect.FileDescriptor
+
+const file_wilayah_v1_wilayah_proto_rawDesc = "" +
+	"\n" +
+	"\x18wilayah/v1/wilayah.proto\x12\n" +
+	"wilayah.v1\"\x84\x03\n" +
+	"\x06Region\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12 \n" +
+	"\vsubdistrict\x18\x02 \x01(\tR\vsubdistrict\x12\x1a\n" +
+	"\bdistrict\x18\x03 \x01(\tR\bdistrict\x12\x12\n" +
+	"\x04city\x18\x04 \x01(\tR\x04city\x12\x1a\n" +
+	"\bprovince\x18\x05 \x01(\tR\bprovince\x12\x1f\n" +
+	"\vpostal_code\x18\x06 \x01(\tR\n" +
+	"postalCode\x12\x1b\n" +
+	"\tfull_text\x18\a \x01(\tR\bfullText\x12\x1f\n" +
+	"\blatitude\x18\b \x01(\x01H\x00R\blatitude\x88\x01\x01\x12!\n" +
+	"\tlongitude\x18\t \x01(\x01H\x01R\tlongitude\x88\x01\x01\x12\x1e\n" +
+	"\barea_km2\x18\n" +
+	" \x01(\x01H\x02R\aareaKm2\x88\x01\x01\x12#\n" +
+	"\n" +
+	"population\x18\v \x01(\x03H\x03R\n" +
+	"population\x88\x01\x01B\v\n" +
+	"\t_latitudeB\f\n" +
+	"\n" +
+	"_longitudeB\v\n" +
+	"\t_area_km2B\r\n" +
+	"\v_population\"S\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\"h\n" +
+	"\x17SearchPostalCodeRequest\x12\x1f\n" +
+	"\vpostal_code\x18\x01 \x01(\tR\n" +
+	"postalCode\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\"\x82\x01\n" +
+	"\x0eSearchResponse\x12,\n" +
+	"\aregions\x18\x01 \x03(\v2\x12.wilayah.v1.RegionR\aregions\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\"&\n" +
+	"\x10GetRegionRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\"5\n" +
+	"\x05Error\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x8d\x01\n" +
+	"\x14LookupRegionResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12,\n" +
+	"\x06region\x18\x02 \x01(\v2\x12.wilayah.v1.RegionH\x00R\x06region\x12)\n" +
+	"\x05error\x18\x03 \x01(\v2\x11.wilayah.v1.ErrorH\x00R\x05errorB\b\n" +
+	"\x06result\"\x92\x01\n" +
+	"\x18LookupPostalCodeResponse\x12\x1f\n" +
+	"\vpostal_code\x18\x01 \x01(\tR\n" +
+	"postalCode\x12,\n" +
+	"\aregions\x18\x02 \x03(\v2\x12.wilayah.v1.RegionR\aregions\x12'\n" +
+	"\x05error\x18\x03 \x01(\v2\x11.wilayah.v1.ErrorR\x05error2\xc5\x05\n" +
+	"\rRegionService\x12?\n" +
+	"\x06Search\x12\x19.wilayah.v1.SearchRequest\x1a\x1a.wilayah.v1.SearchResponse\x12H\n" +
+	"\x0fSearchDistricts\x12\x19.wilayah.v1.SearchRequest\x1a\x1a.wilayah.v1.SearchResponse\x12K\n" +
+	"\x12SearchSubdistricts\x12\x19.wilayah.v1.SearchRequest\x1a\x1a.wilayah.v1.SearchResponse\x12E\n" +
+	"\fSearchCities\x12\x19.wilayah.v1.SearchRequest\x1a\x1a.wilayah.v1.SearchResponse\x12H\n" +
+	"\x0fSearchProvinces\x12\x19.wilayah.v1.SearchRequest\x1a\x1a.wilayah.v1.SearchResponse\x12S\n" +
+	"\x10SearchPostalCode\x12#.wilayah.v1.SearchPostalCodeRequest\x1a\x1a.wilayah.v1.SearchResponse\x12=\n" +
+	"\tGetRegion\x12\x1c.wilayah.v1.GetRegionRequest\x1a\x12.wilayah.v1.Region\x12S\n" +
+	"\rLookupRegions\x12\x1c.wilayah.v1.GetRegionRequest\x1a .wilayah.v1.LookupRegionResponse(\x010\x01\x12b\n" +
+	"\x11LookupPostalCodes\x12#.wilayah.v1.SearchPostalCodeRequest\x1a$.wilayah.v1.LookupPostalCodeResponse(\x010\x01Ba\n" +
+	"\rid.wilayah.v1B\fWilayahProtoP\x01Z@github.com/ilmimris/wilayah-indonesia/proto/wilayah/v1;wilayahv1b\x06proto3"
+
+var (
+	file_wilayah_v1_wilayah_proto_rawDescOnce sync.Once
+	file_wilayah_v1_wilayah_proto_rawDescData []byte
+)
+
+func file_wilayah_v1_wilayah_proto_rawDescGZIP() []byte {
+	file_wilayah_v1_wilayah_proto_rawDescOnce.Do(func() {
+		file_wilayah_v1_wilayah_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_wilayah_v1_wilayah_proto_rawDesc), len(file_wilayah_v1_wilayah_proto_rawDesc)))
+	})
+	return file_wilayah_v1_wilayah_proto_rawDescData
+}
+
+var file_wilayah_v1_wilayah_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_wilayah_v1_wilayah_proto_goTypes = []any{
+	(*Region)(nil),                   // 0: wilayah.v1.Region
+	(*SearchRequest)(nil),            // 1: wilayah.v1.SearchRequest
+	(*SearchPostalCodeRequest)(nil),  // 2: wilayah.v1.SearchPostalCodeRequest
+	(*SearchResponse)(nil),           // 3: wilayah.v1.SearchResponse
+	(*GetRegionRequest)(nil),         // 4: wilayah.v1.GetRegionRequest
+	(*Error)(nil),                    // 5: wilayah.v1.Error
+	(*LookupRegionResponse)(nil),     // 6: wilayah.v1.LookupRegionResponse
+	(*LookupPostalCodeResponse)(nil), // 7: wilayah.v1.LookupPostalCodeResponse
+}
+var file_wilayah_v1_wilayah_proto_depIdxs = []int32{
+	0,  // 0: wilayah.v1.SearchResponse.regions:type_name -> wilayah.v1.Region
+	0,  // 1: wilayah.v1.LookupRegionResponse.region:type_name -> wilayah.v1.Region
+	5,  // 2: wilayah.v1.LookupRegionResponse.error:type_name -> wilayah.v1.Error
+	0,  // 3: wilayah.v1.LookupPostalCodeResponse.regions:type_name -> wilayah.v1.Region
+	5,  // 4: wilayah.v1.LookupPostalCodeResponse.error:type_name -> wilayah.v1.Error
+	1,  // 5: wilayah.v1.RegionService.Search:input_type -> wilayah.v1.SearchRequest
+	1,  // 6: wilayah.v1.RegionService.SearchDistricts:input_type -> wilayah.v1.SearchRequest
+	1,  // 7: wilayah.v1.RegionService.SearchSubdistricts:input_type -> wilayah.v1.SearchRequest
+	1,  // 8: wilayah.v1.RegionService.SearchCities:input_type -> wilayah.v1.SearchRequest
+	1,  // 9: wilayah.v1.RegionService.SearchProvinces:input_type -> wilayah.v1.SearchRequest
+	2,  // 10: wilayah.v1.RegionService.SearchPostalCode:input_type -> wilayah.v1.SearchPostalCodeRequest
+	4,  // 11: wilayah.v1.RegionService.GetRegion:input_type -> wilayah.v1.GetRegionRequest
+	4,  // 12: wilayah.v1.RegionService.LookupRegions:input_type -> wilayah.v1.GetRegionRequest
+	2,  // 13: wilayah.v1.RegionService.LookupPostalCodes:input_type -> wilayah.v1.SearchPostalCodeRequest
+	3,  // 14: wilayah.v1.RegionService.Search:output_type -> wilayah.v1.SearchResponse
+	3,  // 15: wilayah.v1.RegionService.SearchDistricts:output_type -> wilayah.v1.SearchResponse
+	3,  // 16: wilayah.v1.RegionService.SearchSubdistricts:output_type -> wilayah.v1.SearchResponse
+	3,  // 17: wilayah.v1.RegionService.SearchCities:output_type -> wilayah.v1.SearchResponse
+	3,  // 18: wilayah.v1.RegionService.SearchProvinces:output_type -> wilayah.v1.SearchResponse
+	3,  // 19: wilayah.v1.RegionService.SearchPostalCode:output_type -> wilayah.v1.SearchResponse
+	0,  // 20: wilayah.v1.RegionService.GetRegion:output_type -> wilayah.v1.Region
+	6,  // 21: wilayah.v1.RegionService.LookupRegions:output_type -> wilayah.v1.LookupRegionResponse
+	7,  // 22: wilayah.v1.RegionService.LookupPostalCodes:output_type -> wilayah.v1.LookupPostalCodeResponse
+	14, // [14:23] is the sub-list for method output_type
+	5,  // [5:14] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_wilayah_v1_wilayah_proto_init() }
+func file_wilayah_v1_wilayah_proto_init() {
+	if File_wilayah_v1_wilayah_proto != nil {
+		return
+	}
+	file_wilayah_v1_wilayah_proto_msgTypes[0].OneofWrappers = []any{}
+	file_wilayah_v1_wilayah_proto_msgTypes[6].OneofWrappers = []any{
+		(*LookupRegionResponse_Region)(nil),
+		(*LookupRegionResponse_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_wilayah_v1_wilayah_proto_rawDesc), len(file_wilayah_v1_wilayah_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_wilayah_v1_wilayah_proto_goTypes,
+		DependencyIndexes: file_wilayah_v1_wilayah_proto_depIdxs,
+		MessageInfos:      file_wilayah_v1_wilayah_proto_msgTypes,
+	}.Build()
+	File_wilayah_v1_wilayah_proto = out.File
+	file_wilayah_v1_wilayah_proto_goTypes = nil
+	file_wilayah_v1_wilayah_proto_depIdxs = nil
+}
@@ -0,0 +1,446 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: wilayah/v1/wilayah.proto
+
+package wilayahv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RegionService_Search_FullMethodName             = "/wilayah.v1.RegionService/Search"
+	RegionService_SearchDistricts_FullMethodName    = "/wilayah.v1.RegionService/SearchDistricts"
+	RegionService_SearchSubdistricts_FullMethodName = "/wilayah.v1.RegionService/SearchSubdistricts"
+	RegionService_SearchCities_FullMethodName       = "/wilayah.v1.RegionService/SearchCities"
+	RegionService_SearchProvinces_FullMethodName    = "/wilayah.v1.RegionService/SearchProvinces"
+	RegionService_SearchPostalCode_FullMethodName   = "/wilayah.v1.RegionService/SearchPostalCode"
+	RegionService_GetRegion_FullMethodName          = "/wilayah.v1.RegionService/GetRegion"
+	RegionService_LookupRegions_FullMethodName      = "/wilayah.v1.RegionService/LookupRegions"
+	RegionService_LookupPostalCodes_FullMethodName  = "/wilayah.v1.RegionService/LookupPostalCodes"
+)
+
+// RegionServiceClient is the client API for RegionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RegionService searches and looks up Indonesian administrative regions. It
+// mirrors the /v1 HTTP endpoints and is backed by the same service.
+type RegionServiceClient interface {
+	// Search runs a full-text search across all region names.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// SearchDistricts searches regions by district (kecamatan) name.
+	SearchDistricts(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// SearchSubdistricts searches regions by subdistrict (kelurahan/desa) name.
+	SearchSubdistricts(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// SearchCities searches regions by city or regency name.
+	SearchCities(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// SearchProvinces searches regions by province name.
+	SearchProvinces(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// SearchPostalCode lists the subdistricts with a postal code. An unknown
+	// postal code fails with NOT_FOUND.
+	SearchPostalCode(ctx context.Context, in *SearchPostalCodeRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// GetRegion looks up a region by its Kemendagri code at any level.
+	GetRegion(ctx context.Context, in *GetRegionRequest, opts ...grpc.CallOption) (*Region, error)
+	// LookupRegions looks up a stream of codes, answering each request with a
+	// response in the same order. Failed lookups are reported in the response
+	// without ending the stream.
+	LookupRegions(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[GetRegionRequest, LookupRegionResponse], error)
+	// LookupPostalCodes looks up a stream of postal codes, answering each
+	// request with a response in the same order.
+	LookupPostalCodes(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SearchPostalCodeRequest, LookupPostalCodeResponse], error)
+}
+
+type regionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRegionServiceClient(cc grpc.ClientConnInterface) RegionServiceClient {
+	return &regionServiceClient{cc}
+}
+
+func (c *regionServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, RegionService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *regionServiceClient) SearchDistricts(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, RegionService_SearchDistricts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *regionServiceClient) SearchSubdistricts(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, RegionService_SearchSubdistricts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *regionServiceClient) SearchCities(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, RegionService_SearchCities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *regionServiceClient) SearchProvinces(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, RegionService_SearchProvinces_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *regionServiceClient) SearchPostalCode(ctx context.Context, in *SearchPostalCodeRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, RegionService_SearchPostalCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *regionServiceClient) GetRegion(ctx context.Context, in *GetRegionRequest, opts ...grpc.CallOption) (*Region, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Region)
+	err := c.cc.Invoke(ctx, RegionService_GetRegion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *regionServiceClient) LookupRegions(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[GetRegionRequest, LookupRegionResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RegionService_ServiceDesc.Streams[0], RegionService_LookupRegions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetRegionRequest, LookupRegionResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RegionService_LookupRegionsClient = grpc.BidiStreamingClient[GetRegionRequest, LookupRegionResponse]
+
+func (c *regionServiceClient) LookupPostalCodes(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SearchPostalCodeRequest, LookupPostalCodeResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RegionService_ServiceDesc.Streams[1], RegionService_LookupPostalCodes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SearchPostalCodeRequest, LookupPostalCodeResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RegionService_LookupPostalCodesClient = grpc.BidiStreamingClient[SearchPostalCodeRequest, LookupPostalCodeResponse]
+
+// RegionServiceServer is the server API for RegionService service.
+// All implementations must embed UnimplementedRegionServiceServer
+// for forward compatibility.
+//
+// RegionService searches and looks up Indonesian administrative regions. It
+// mirrors the /v1 HTTP endpoints and is backed by the same service.
+type RegionServiceServer interface {
+	// Search runs a full-text search across all region names.
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// SearchDistricts searches regions by district (kecamatan) name.
+	SearchDistricts(context.Context, *SearchRequest) (*SearchResponse, error)
+	// SearchSubdistricts searches regions by subdistrict (kelurahan/desa) name.
+	SearchSubdistricts(context.Context, *SearchRequest) (*SearchResponse, error)
+	// SearchCities searches regions by city or regency name.
+	SearchCities(context.Context, *SearchRequest) (*SearchResponse, error)
+	// SearchProvinces searches regions by province name.
+	SearchProvinces(context.Context, *SearchRequest) (*SearchResponse, error)
+	// SearchPostalCode lists the subdistricts with a postal code. An unknown
+	// postal code fails with NOT_FOUND.
+	SearchPostalCode(context.Context, *SearchPostalCodeRequest) (*SearchResponse, error)
+	// GetRegion looks up a region by its Kemendagri code at any level.
+	GetRegion(context.Context, *GetRegionRequest) (*Region, error)
+	// LookupRegions looks up a stream of codes, answering each request with a
+	// response in the same order. Failed lookups are reported in the response
+	// without ending the stream.
+	LookupRegions(grpc.BidiStreamingServer[GetRegionRequest, LookupRegionResponse]) error
+	// LookupPostalCodes looks up a stream of postal codes, answering each
+	// request with a response in the same order.
+	LookupPostalCodes(grpc.BidiStreamingServer[SearchPostalCodeRequest, LookupPostalCodeResponse]) error
+	mustEmbedUnimplementedRegionServiceServer()
+}
+
+// UnimplementedRegionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRegionServiceServer struct{}
+
+func (UnimplementedRegionServiceServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedRegionServiceServer) SearchDistricts(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchDistricts not implemented")
+}
+func (UnimplementedRegionServiceServer) SearchSubdistricts(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchSubdistricts not implemented")
+}
+func (UnimplementedRegionServiceServer) SearchCities(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchCities not implemented")
+}
+func (UnimplementedRegionServiceServer) SearchProvinces(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchProvinces not implemented")
+}
+func (UnimplementedRegionServiceServer) SearchPostalCode(context.Context, *SearchPostalCodeRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchPostalCode not implemented")
+}
+func (UnimplementedRegionServiceServer) GetRegion(context.Context, *GetRegionRequest) (*Region, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRegion not implemented")
+}
+func (UnimplementedRegionServiceServer) LookupRegions(grpc.BidiStreamingServer[GetRegionRequest, LookupRegionResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method LookupRegions not implemented")
+}
+func (UnimplementedRegionServiceServer) LookupPostalCodes(grpc.BidiStreamingServer[SearchPostalCodeRequest, LookupPostalCodeResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method LookupPostalCodes not implemented")
+}
+func (UnimplementedRegionServiceServer) mustEmbedUnimplementedRegionServiceServer() {}
+func (UnimplementedRegionServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeRegionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RegionServiceServer will
+// result in compilation errors.
+type UnsafeRegionServiceServer interface {
+	mustEmbedUnimplementedRegionServiceServer()
+}
+
+func RegisterRegionServiceServer(s grpc.ServiceRegistrar, srv RegionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRegionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RegionService_ServiceDesc, srv)
+}
+
+func _RegionService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegionServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RegionService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegionServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegionService_SearchDistricts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegionServiceServer).SearchDistricts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RegionService_SearchDistricts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegionServiceServer).SearchDistricts(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegionService_SearchSubdistricts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegionServiceServer).SearchSubdistricts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RegionService_SearchSubdistricts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegionServiceServer).SearchSubdistricts(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegionService_SearchCities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegionServiceServer).SearchCities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RegionService_SearchCities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegionServiceServer).SearchCities(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegionService_SearchProvinces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegionServiceServer).SearchProvinces(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RegionService_SearchProvinces_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegionServiceServer).SearchProvinces(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegionService_SearchPostalCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchPostalCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegionServiceServer).SearchPostalCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RegionService_SearchPostalCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegionServiceServer).SearchPostalCode(ctx, req.(*SearchPostalCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegionService_GetRegion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRegionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegionServiceServer).GetRegion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RegionService_GetRegion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegionServiceServer).GetRegion(ctx, req.(*GetRegionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegionService_LookupRegions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RegionServiceServer).LookupRegions(&grpc.GenericServerStream[GetRegionRequest, LookupRegionResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RegionService_LookupRegionsServer = grpc.BidiStreamingServer[GetRegionRequest, LookupRegionResponse]
+
+func _RegionService_LookupPostalCodes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RegionServiceServer).LookupPostalCodes(&grpc.GenericServerStream[SearchPostalCodeRequest, LookupPostalCodeResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RegionService_LookupPostalCodesServer = grpc.BidiStreamingServer[SearchPostalCodeRequest, LookupPostalCodeResponse]
+
+// RegionService_ServiceDesc is the grpc.ServiceDesc for RegionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RegionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "wilayah.v1.RegionService",
+	HandlerType: (*RegionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Search",
+			Handler:    _RegionService_Search_Handler,
+		},
+		{
+			MethodName: "SearchDistricts",
+			Handler:    _RegionService_SearchDistricts_Handler,
+		},
+		{
+			MethodName: "SearchSubdistricts",
+			Handler:    _RegionService_SearchSubdistricts_Handler,
+		},
+		{
+			MethodName: "SearchCities",
+			Handler:    _RegionService_SearchCities_Handler,
+		},
+		{
+			MethodName: "SearchProvinces",
+			Handler:    _RegionService_SearchProvinces_Handler,
+		},
+		{
+			MethodName: "SearchPostalCode",
+			Handler:    _RegionService_SearchPostalCode_Handler,
+		},
+		{
+			MethodName: "GetRegion",
+			Handler:    _RegionService_GetRegion_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "LookupRegions",
+			Handler:       _RegionService_LookupRegions_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "LookupPostalCodes",
+			Handler:       _RegionService_LookupPostalCodes_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "wilayah/v1/wilayah.proto",
+}
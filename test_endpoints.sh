@@ -65,4 +65,37 @@ curl -s "http://${HOST}:${PORT}/v1/search/postal/123" | jq '.'
 echo -e "\n14. Testing postal code search endpoint with non-existent postal code:"
 curl -s "http://${HOST}:${PORT}/v1/search/postal/99999" | jq '.'
 
+# Test 15: Export endpoint streaming cities as CSV
+echo -e "\n15. Testing export endpoint with CSV cities:"
+curl -s "http://${HOST}:${PORT}/v1/export?format=csv&level=city" | head -5
+
+# Test 16: Export endpoint returning 304 for an unchanged dataset
+echo -e "\n16. Testing export endpoint with a matching ETag:"
+ETAG=$(curl -sI "http://${HOST}:${PORT}/v1/export?format=ndjson&level=province" | grep -i '^etag:' | cut -d' ' -f2- | tr -d '\r')
+curl -s -o /dev/null -w "%{http_code}\n" -H "If-None-Match: ${ETAG}" "http://${HOST}:${PORT}/v1/export?format=ndjson&level=province"
+
+# Test 17: Region lookup endpoint by city code
+echo -e "\n17. Testing region lookup endpoint with a city code:"
+curl -s "http://${HOST}:${PORT}/v1/regions/32.73" | jq '.'
+
+# Test 18: Attribute sets available through ?include=
+echo -e "\n18. Testing attribute set listing endpoint:"
+curl -s "http://${HOST}:${PORT}/v1/attributes" | jq '.'
+
+# Test 19: Region stats endpoint for a city
+echo -e "\n19. Testing region stats endpoint with a city code:"
+curl -s "http://${HOST}:${PORT}/v1/regions/32.73/stats" | jq '.'
+
+# Test 20: Island search endpoint
+echo -e "\n20. Testing island search endpoint:"
+curl -s "http://${HOST}:${PORT}/v1/islands?q=pramuka" | jq '.'
+
+# Test 21: Islands of a city
+echo -e "\n21. Testing city islands endpoint:"
+curl -s "http://${HOST}:${PORT}/v1/cities/31.01/islands" | jq '.'
+
+# Test 22: Enveloped /v2 search with pagination
+echo -e "\n22. Testing /v2 city search with limit and offset:"
+curl -s "http://${HOST}:${PORT}/v2/search/city?q=bandung&limit=2&offset=1" | jq '.meta'
+
 echo -e "\n\nTest completed!"
\ No newline at end of file